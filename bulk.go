@@ -0,0 +1,85 @@
+package rivers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"golang.org/x/exp/slices"
+)
+
+// StationLevels holds water level readings retrieved for a station,
+// or the error that occurred while retrieving them. Rejected holds
+// csv records skipped when the readings were parsed in lenient mode.
+type StationLevels struct {
+	StationID StationRef
+	Readings  []WaterLevelReading
+	Rejected  []RejectedRow
+	Err       error
+}
+
+// GetLevelsForStations knows how to return water level readings recorded
+// for the given period for many stations at once.
+//
+// Stations are fetched concurrently by up to Client.Concurrency workers
+// and requests are paced by Client.RateLimiter. A failure to fetch one
+// station does not stop the batch; the error is reported in the
+// station result. Results are returned in the order of stationIDs.
+// It errors if the period is not valid or the context is done
+// before all stations are fetched.
+func (c *Client) GetLevelsForStations(ctx context.Context, stationIDs []StationRef, period Period) ([]StationLevels, error) {
+	if !slices.Contains(validPeriods, period) {
+		return nil, fmt.Errorf("invalid period %q, expecting one of 'day', 'week', 'month'", period)
+	}
+	workers := c.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(stationIDs) {
+		workers = len(stationIDs)
+	}
+
+	results := make([]StationLevels, len(stationIDs))
+	done := make([]bool, len(stationIDs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				readings, rejected, err := c.getLevelHistory(ctx, stationIDs[i], period)
+				results[i] = StationLevels{
+					StationID: stationIDs[i],
+					Readings:  readings,
+					Rejected:  rejected,
+					Err:       err,
+				}
+				done[i] = true
+			}
+		}()
+	}
+
+send:
+	for i := range stationIDs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		// Stations not picked up by workers before
+		// the context was done report the context error.
+		for i := range results {
+			if !done[i] {
+				results[i] = StationLevels{StationID: stationIDs[i], Err: err}
+			}
+		}
+		return results, fmt.Errorf("retrieving water levels for stations: %w", err)
+	}
+	return results, nil
+}
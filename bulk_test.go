@@ -0,0 +1,107 @@
+package rivers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qba73/rivers"
+)
+
+func TestClient_GetLevelsForStationsReportsPerStationResults(t *testing.T) {
+	t.Parallel()
+	var inFlight, maxInFlight int32
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if strings.Contains(r.URL.Path, "99999") {
+			http.NotFound(rw, r)
+			return
+		}
+		http.ServeFile(rw, r, "testdata/month_01041_0001.csv")
+	}))
+	t.Cleanup(ts.Close)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+	client.Concurrency = 2
+	client.RateLimiter = nil
+
+	ids := []rivers.StationRef{1041, 99999, 1043, 3055, 3058}
+	got, err := client.GetLevelsForStations(context.Background(), ids, rivers.PeriodMonth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(ids) {
+		t.Fatalf("want %d results, got %d", len(ids), len(got))
+	}
+	for i, res := range got {
+		if res.StationID != ids[i] {
+			t.Errorf("want result %d for station %s, got %s", i, ids[i], res.StationID)
+		}
+		if ids[i] == 99999 {
+			if !errors.Is(res.Err, rivers.ErrStationNotFound) {
+				t.Errorf("want ErrStationNotFound for station %s, got %v", ids[i], res.Err)
+			}
+			continue
+		}
+		if res.Err != nil {
+			t.Errorf("station %s: unexpected error %v", ids[i], res.Err)
+		}
+		if len(res.Readings) != 4 {
+			t.Errorf("station %s: want 4 readings, got %d", ids[i], len(res.Readings))
+		}
+	}
+	if maxInFlight > 2 {
+		t.Errorf("want at most 2 concurrent requests, got %d", maxInFlight)
+	}
+}
+
+func TestClient_GetLevelsForStationsErrorsOnInvalidPeriod(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(t)
+	_, err := client.GetLevelsForStations(context.Background(), []rivers.StationRef{1041}, rivers.Period("year"))
+	if err == nil {
+		t.Fatal("want error on invalid period")
+	}
+}
+
+func TestRateLimiter_PacesRequests(t *testing.T) {
+	t.Parallel()
+	limiter := rivers.NewRateLimiter(50, 1)
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// First request uses the burst token, next five wait 20ms each.
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("want requests paced to 50/s, took %s", elapsed)
+	}
+}
+
+func TestRateLimiter_StopsWaitingWhenContextIsDone(t *testing.T) {
+	t.Parallel()
+	limiter := rivers.NewRateLimiter(0.001, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := limiter.Wait(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want context.DeadlineExceeded, got %v", err)
+	}
+}
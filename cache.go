@@ -0,0 +1,289 @@
+package rivers
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CacheEntry holds a cached response body together
+// with validators used for conditional requests.
+type CacheEntry struct {
+	Body         []byte    `json:"body"`
+	ContentType  string    `json:"content_type,omitempty"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	StoredAt     time.Time `json:"stored_at"`
+}
+
+// CacheStore is the interface that wraps Get and Set methods.
+//
+// Get returns the entry stored under the given key and reports
+// whether the entry exists. Set stores the entry under the given key.
+type CacheStore interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, entry CacheEntry) error
+}
+
+// HTTPCache holds settings of the client response cache.
+//
+// Responses younger than TTL are served from the cache without
+// contacting the service. Older responses are revalidated with
+// conditional requests using ETag and Last-Modified validators.
+// If the service is unreachable or fails, responses younger than
+// StaleIfError are served from the cache.
+type HTTPCache struct {
+	Store        CacheStore
+	TTL          time.Duration
+	StaleIfError time.Duration
+}
+
+func (hc *HTTPCache) fresh(e CacheEntry, now time.Time) bool {
+	return now.Sub(e.StoredAt) < hc.TTL
+}
+
+func (hc *HTTPCache) usableOnError(e CacheEntry, now time.Time) bool {
+	return now.Sub(e.StoredAt) < hc.StaleIfError
+}
+
+// response builds the HTTP response for the given request from the cache entry.
+func (e CacheEntry) response(req *http.Request) *http.Response {
+	header := make(http.Header)
+	if e.ContentType != "" {
+		header.Set("Content-Type", e.ContentType)
+	}
+	if e.ETag != "" {
+		header.Set("ETag", e.ETag)
+	}
+	if e.LastModified != "" {
+		header.Set("Last-Modified", e.LastModified)
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// sendRequest sends the request using the client response cache,
+// if the cache is configured. Otherwise it sends the request
+// with retries.
+func (c *Client) sendRequest(req *http.Request) (*http.Response, error) {
+	if c.Cache == nil || c.Cache.Store == nil || req.Method != http.MethodGet {
+		return c.sendRequestWithBackoff(req)
+	}
+	store := c.Cache.Store
+	key := req.URL.String()
+	entry, cached := store.Get(key)
+	if cached && c.Cache.fresh(entry, time.Now()) {
+		return entry.response(req), nil
+	}
+	if cached {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	res, err := c.sendRequestWithBackoff(req)
+	if err != nil {
+		if cached && c.Cache.usableOnError(entry, time.Now()) {
+			c.logf("rivers: serving stale response for %s: %v", key, err)
+			return entry.response(req), nil
+		}
+		return nil, err
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotModified && cached:
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+		entry.StoredAt = time.Now()
+		// Failing to refresh the entry only means the next
+		// request is revalidated again, so we only log the error.
+		if err := store.Set(key, entry); err != nil {
+			c.logf("rivers: refreshing cache entry for %s: %v", key, err)
+		}
+		return entry.response(req), nil
+	case res.StatusCode >= http.StatusInternalServerError && cached && c.Cache.usableOnError(entry, time.Now()):
+		c.logf("rivers: serving stale response for %s: status %d", key, res.StatusCode)
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+		return entry.response(req), nil
+	case res.StatusCode != http.StatusOK:
+		return res, nil
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	entry = CacheEntry{
+		Body:         body,
+		ContentType:  res.Header.Get("Content-Type"),
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+		StoredAt:     time.Now(),
+	}
+	if err := store.Set(key, entry); err != nil {
+		c.logf("rivers: storing cache entry for %s: %v", key, err)
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return res, nil
+}
+
+// DefaultMemoryCacheEntries is the number of entries kept by
+// a MemoryCache created without a valid limit.
+const DefaultMemoryCacheEntries = 1000
+
+// MemoryCache is a CacheStore that keeps entries in memory.
+// When the cache is full, the least recently used entry is evicted.
+// It is safe for concurrent use.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	// order holds memoryCacheItems, the most recently used first.
+	order *list.List
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewMemoryCache creates a new, empty in-memory cache store holding
+// up to maxEntries entries. Values lower than 1 mean
+// DefaultMemoryCacheEntries.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries < 1 {
+		maxEntries = DefaultMemoryCacheEntries
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get returns the entry stored under the given key.
+func (m *MemoryCache) Get(key string) (CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return CacheEntry{}, false
+	}
+	m.order.MoveToFront(el)
+	return el.Value.(*memoryCacheItem).entry, true
+}
+
+// Set stores the entry under the given key, evicting
+// the least recently used entry if the cache is full.
+func (m *MemoryCache) Set(key string, entry CacheEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.entries[key]; ok {
+		el.Value.(*memoryCacheItem).entry = entry
+		m.order.MoveToFront(el)
+		return nil
+	}
+	m.entries[key] = m.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+	if m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+	return nil
+}
+
+// Len returns the number of entries in the cache.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// DiskCache is a CacheStore that keeps entries
+// as JSON files in a directory.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache takes a path to a directory and creates a new disk
+// cache store. The directory is created if it does not exist.
+// It errors if the path is empty.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if dir == "" {
+		return nil, errors.New("empty cache directory path")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+// Get returns the entry stored under the given key. Entries
+// that can't be read are reported as missing.
+func (d *DiskCache) Get(key string) (CacheEntry, bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return CacheEntry{}, false
+	}
+	var e CacheEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return CacheEntry{}, false
+	}
+	return e, true
+}
+
+// Set stores the entry under the given key. The entry is written
+// to a temporary file first, so readers never see partial entries.
+func (d *DiskCache) Set(key string, entry CacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding cache entry: %w", err)
+	}
+	f, err := os.CreateTemp(d.dir, "entry-*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := os.Rename(f.Name(), d.path(key)); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	return nil
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,185 @@
+package rivers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/rivers"
+)
+
+func TestClient_RevalidatesCachedResponseWithETag(t *testing.T) {
+	t.Parallel()
+	var requests, notModified int32
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+		rw.Header().Set("ETag", `"v1"`)
+		http.ServeFile(rw, r, "testdata/latest_short.json")
+	}))
+	t.Cleanup(ts.Close)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+	client.Cache = &rivers.HTTPCache{Store: rivers.NewMemoryCache(rivers.DefaultMemoryCacheEntries)}
+
+	want, err := client.GetLatestWaterLevels(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.GetLatestWaterLevels(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if requests != 2 || notModified != 1 {
+		t.Errorf("want 2 requests with 1 not modified response, got %d and %d", requests, notModified)
+	}
+}
+
+func TestClient_ServesFreshResponseFromCacheWithoutRequest(t *testing.T) {
+	t.Parallel()
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.ServeFile(rw, r, "testdata/day_01041_0001.csv")
+	}))
+	t.Cleanup(ts.Close)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+	client.Cache = &rivers.HTTPCache{
+		Store: rivers.NewMemoryCache(rivers.DefaultMemoryCacheEntries),
+		TTL:   time.Minute,
+	}
+
+	for i := 0; i < 3; i++ {
+		got, err := client.GetDayLevel(context.Background(), 1041)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 4 {
+			t.Fatalf("want 4 readings, got %d", len(got))
+		}
+	}
+	if requests != 1 {
+		t.Errorf("want 1 request, got %d", requests)
+	}
+}
+
+func TestClient_ServesStaleResponseFromCacheOnServerError(t *testing.T) {
+	t.Parallel()
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) > 1 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		http.ServeFile(rw, r, "testdata/day_01041_0001.csv")
+	}))
+	t.Cleanup(ts.Close)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+	client.Retry = rivers.NoRetryPolicy()
+	client.Cache = &rivers.HTTPCache{
+		Store:        rivers.NewMemoryCache(rivers.DefaultMemoryCacheEntries),
+		StaleIfError: time.Hour,
+	}
+
+	want, err := client.GetDayLevel(context.Background(), 1041)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.GetDayLevel(context.Background(), 1041)
+	if err != nil {
+		t.Fatalf("want response from cache, got error %v", err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	client.Cache.StaleIfError = 0
+	_, err = client.GetDayLevel(context.Background(), 1041)
+	if err == nil {
+		t.Error("want error when stale responses are not allowed")
+	}
+}
+
+func TestMemoryCache_EvictsLeastRecentlyUsedEntry(t *testing.T) {
+	t.Parallel()
+	cache := rivers.NewMemoryCache(2)
+	for _, key := range []string{"a", "b"} {
+		if err := cache.Set(key, rivers.CacheEntry{Body: []byte(key)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Using "a" makes "b" the least recently used entry.
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("want entry a")
+	}
+	if err := cache.Set("c", rivers.CacheEntry{Body: []byte("c")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get("b"); ok {
+		t.Error("want entry b evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		e, ok := cache.Get(key)
+		if !ok || string(e.Body) != key {
+			t.Errorf("want entry %s kept, got %q, %v", key, e.Body, ok)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Errorf("want 2 entries, got %d", cache.Len())
+	}
+}
+
+func TestDiskCache_PersistsEntries(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	cache, err := rivers.NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := rivers.CacheEntry{
+		Body:         []byte("datetime,value\n"),
+		ContentType:  "text/csv",
+		ETag:         `"v1"`,
+		LastModified: "Wed, 21 Oct 2015 07:28:00 GMT",
+		StoredAt:     time.Date(2022, 06, 28, 04, 45, 00, 00, time.UTC),
+	}
+	if err := cache.Set("http://example.com/data.csv", want); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := rivers.NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := reopened.Get("http://example.com/data.csv")
+	if !ok {
+		t.Fatal("want entry stored on disk")
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if _, ok := reopened.Get("http://example.com/other.csv"); ok {
+		t.Error("want no entry for unknown key")
+	}
+}
+
+func TestDiskCache_ErrorsOnEmptyPath(t *testing.T) {
+	t.Parallel()
+	_, err := rivers.NewDiskCache("")
+	if err == nil {
+		t.Fatal("want error on empty path")
+	}
+}
@@ -0,0 +1,142 @@
+package rivers
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	//go:embed data/stations.json
+	stationsData []byte
+
+	//go:embed data/groups.json
+	groupsData []byte
+)
+
+// Group represents a group of stations located
+// along the same river or catchment.
+type Group struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// catalogue holds bundled station and group data used to
+// resolve names found in group csv files to station references.
+type catalogue struct {
+	stationRefs map[string]StationRef
+	groups      []Group
+	groupNames  map[int]string
+}
+
+var (
+	loadCatalogueOnce sync.Once
+	bundledCatalogue  catalogue
+	errCatalogue      error
+)
+
+// loadCatalogue decodes bundled station and group data.
+// The data is decoded only once, on first use.
+func loadCatalogue() (catalogue, error) {
+	loadCatalogueOnce.Do(func() {
+		bundledCatalogue, errCatalogue = decodeCatalogue(stationsData, groupsData)
+	})
+	return bundledCatalogue, errCatalogue
+}
+
+func decodeCatalogue(stationsJSON, groupsJSON []byte) (catalogue, error) {
+	var res stationsResponse
+	if err := json.Unmarshal(stationsJSON, &res); err != nil {
+		return catalogue{}, fmt.Errorf("decoding bundled stations: %w", err)
+	}
+	stations, err := parseStations(res)
+	if err != nil {
+		return catalogue{}, fmt.Errorf("decoding bundled stations: %w", err)
+	}
+
+	refs := make(map[string]StationRef, len(stations))
+	// Station names are not unique. We do not resolve names shared
+	// by more than one station as we can't tell which one is meant.
+	ambiguous := make(map[string]bool)
+	for _, s := range stations {
+		name := normalizeStationName(s.Name)
+		if _, ok := refs[name]; ok {
+			ambiguous[name] = true
+		}
+		refs[name] = s.ID
+	}
+	for name := range ambiguous {
+		delete(refs, name)
+	}
+
+	var groups []Group
+	if err := json.Unmarshal(groupsJSON, &groups); err != nil {
+		return catalogue{}, fmt.Errorf("decoding bundled groups: %w", err)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].ID < groups[j].ID
+	})
+	names := make(map[int]string, len(groups))
+	for _, g := range groups {
+		names[g.ID] = g.Name
+	}
+
+	return catalogue{
+		stationRefs: refs,
+		groups:      groups,
+		groupNames:  names,
+	}, nil
+}
+
+// group returns the group with the given id.
+func (c catalogue) group(id int) (Group, error) {
+	name, ok := c.groupNames[id]
+	if !ok {
+		return Group{}, fmt.Errorf("group id %d: %w", id, ErrGroupNotFound)
+	}
+	return Group{ID: id, Name: name}, nil
+}
+
+// groupByName returns the group with the given name.
+// Names are compared case-insensitively.
+func (c catalogue) groupByName(name string) (Group, error) {
+	for _, g := range c.groups {
+		if strings.EqualFold(g.Name, strings.TrimSpace(name)) {
+			return g, nil
+		}
+	}
+	return Group{}, fmt.Errorf("group name %q: %w", name, ErrGroupNotFound)
+}
+
+// Groups returns all groups of stations known to the water level
+// service, ordered by group id.
+func Groups() ([]Group, error) {
+	cat, err := loadCatalogue()
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]Group, len(cat.groups))
+	copy(groups, cat.groups)
+	return groups, nil
+}
+
+// ErrGroupNotFound is the error used for indicating
+// that the given group of stations does not exist.
+var ErrGroupNotFound = errors.New("group not found")
+
+// stationRef returns the station reference for the given station name.
+func (c catalogue) stationRef(name string) (StationRef, bool) {
+	ref, ok := c.stationRefs[normalizeStationName(name)]
+	return ref, ok
+}
+
+// normalizeStationName is a helper func that makes station names
+// comparable. Names in csv headers and in the GeoJSON feed differ
+// in letter case and in the number of spaces between words.
+func normalizeStationName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
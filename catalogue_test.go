@@ -0,0 +1,42 @@
+package rivers_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/rivers"
+)
+
+func TestGroups_ReturnsAllGroupsOrderedByID(t *testing.T) {
+	t.Parallel()
+	got, err := rivers.Groups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantLen := 27
+	if wantLen != len(got) {
+		t.Fatalf("want %d groups, got %d", wantLen, len(got))
+	}
+	wantFirst := []rivers.Group{
+		{ID: 1, Name: "Nore"},
+		{ID: 2, Name: "Shannon"},
+		{ID: 3, Name: "Turlough"},
+		{ID: 4, Name: "Barrow"},
+	}
+	if !cmp.Equal(wantFirst, got[:4]) {
+		t.Error(cmp.Diff(wantFirst, got[:4]))
+	}
+}
+
+func TestGroups_DoesNotContainGroup7(t *testing.T) {
+	t.Parallel()
+	got, err := rivers.Groups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, g := range got {
+		if g.ID == 7 {
+			t.Fatalf("want no group with id 7, got %+v", g)
+		}
+	}
+}
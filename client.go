@@ -6,10 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"math/rand"
+	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -25,8 +26,10 @@ const (
 	tempSensor    = 2
 	voltageSensor = 3
 
-	sensorTypeLevel = "0001"
-	sensorTypeTemp  = "0002"
+	sensorTypeLevel   = "0001"
+	sensorTypeTemp    = "0002"
+	sensorTypeVoltage = "0003"
+	sensorTypeLevelOD = "OD"
 )
 
 type response struct {
@@ -43,38 +46,149 @@ type response struct {
 	}
 }
 
+type stationsResponse struct {
+	Features []struct {
+		Properties struct {
+			Name     string `json:"name"`
+			Ref      string `json:"ref"`
+			RegionID int    `json:"region_id"`
+		} `json:"properties"`
+		Geometry struct {
+			Coordinates []float64 `json:"coordinates"`
+		} `json:"geometry"`
+	}
+}
+
 type errResponse struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 }
 
 // Sensor represents a sensor installed in a station.
+// In JSON the StationID is a string padded the same way as in
+// the GeoJSON feed, or an empty string if the station is unknown.
 type Sensor struct {
-	StationID   string `json:"station_id"`
-	StationName string `json:"station_name"`
-	Type        string `json:"type"`
-	Value       string `json:"value"`
-	Timestamp   string `json:"timestamp"`
-	ErrorCode   int    `json:"err_code"`
-	RegionID    string `json:"region_id"`
+	StationID   StationRef `json:"station_id"`
+	StationName string     `json:"station_name"`
+	Type        string     `json:"type"`
+	Value       string     `json:"value"`
+	Timestamp   string     `json:"timestamp"`
+	ErrorCode   int        `json:"err_code"`
+	RegionID    string     `json:"region_id"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// It encodes the StationID as a padded string.
+func (s Sensor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		StationID   string `json:"station_id"`
+		StationName string `json:"station_name"`
+		Type        string `json:"type"`
+		Value       string `json:"value"`
+		Timestamp   string `json:"timestamp"`
+		ErrorCode   int    `json:"err_code"`
+		RegionID    string `json:"region_id"`
+	}{s.StationID.jsonString(), s.StationName, s.Type, s.Value, s.Timestamp, s.ErrorCode, s.RegionID})
 }
 
 // Station represents a station with multiple sensors.
+// In JSON the ID is a string padded the same way as in
+// the GeoJSON feed, or an empty string if the station is unknown.
 type Station struct {
-	ID         string   `json:"id"`
-	Name       string   `json:"name"`
-	RegionID   int      `json:"region_id"`
-	RegionName string   `json:"region_name"`
-	Lat        float64  `json:"lat"`
-	Long       float64  `json:"long"`
-	Sensors    []Sensor `json:"sensors"`
+	ID         StationRef `json:"id"`
+	Name       string     `json:"name"`
+	RegionID   int        `json:"region_id"`
+	RegionName string     `json:"region_name"`
+	Lat        float64    `json:"lat"`
+	Long       float64    `json:"long"`
+	Sensors    []Sensor   `json:"sensors"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// It encodes the ID as a padded string.
+func (s Station) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ID         string   `json:"id"`
+		Name       string   `json:"name"`
+		RegionID   int      `json:"region_id"`
+		RegionName string   `json:"region_name"`
+		Lat        float64  `json:"lat"`
+		Long       float64  `json:"long"`
+		Sensors    []Sensor `json:"sensors"`
+	}{s.ID.jsonString(), s.Name, s.RegionID, s.RegionName, s.Lat, s.Long, s.Sensors})
+}
+
+// SensorKind represents the kind of a sensor installed in a station.
+type SensorKind int
+
+const (
+	SensorUnknown SensorKind = iota
+	SensorLevel
+	SensorTemperature
+	SensorVoltage
+	SensorLevelOD
+)
+
+var sensorKindNames = map[SensorKind]string{
+	SensorUnknown:     "unknown",
+	SensorLevel:       "level",
+	SensorTemperature: "temperature",
+	SensorVoltage:     "voltage",
+	SensorLevelOD:     "level_od",
+}
+
+func (k SensorKind) String() string {
+	name, ok := sensorKindNames[k]
+	if !ok {
+		return sensorKindNames[SensorUnknown]
+	}
+	return name
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (k SensorKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (k *SensorKind) UnmarshalText(text []byte) error {
+	for kind, name := range sensorKindNames {
+		if name == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid sensor kind %q", text)
+}
+
+// parseSensorKind takes a sensor reference used in the GeoJSON feed
+// and returns the corresponding sensor kind. Sensor references
+// the library does not know about map to SensorUnknown.
+func parseSensorKind(ref string) SensorKind {
+	switch ref {
+	case sensorTypeLevel:
+		return SensorLevel
+	case sensorTypeTemp:
+		return SensorTemperature
+	case sensorTypeVoltage:
+		return SensorVoltage
+	case sensorTypeLevelOD:
+		return SensorLevelOD
+	default:
+		return SensorUnknown
+	}
 }
 
 // SensorReading represents data received from a sensor.
+//
+// Value holds the raw reading in the sensor unit: meters for
+// water level, degrees Celsius for temperature and volts for voltage.
+// ErrCode is the err_code published with the reading, as is.
 type SensorReading struct {
-	StationID   string
+	StationID   StationRef
 	StationName string
 	SensorID    string
+	Kind        SensorKind
 	RegionID    int
 	Value       float64
 	Timestamp   time.Time
@@ -86,19 +200,72 @@ type Client struct {
 	UserAgent  string
 	BaseURL    string
 	HTTPClient *http.Client
+	Retry      RetryPolicy
+
+	// FallbackURLs holds base URLs of mirrors of the service. They are
+	// tried in order when the BaseURL endpoint fails with a transport
+	// error or a 5xx response.
+	FallbackURLs []string
+
+	// Cache holds optional response cache settings.
+	// Responses are not cached if Cache is nil.
+	Cache *HTTPCache
+
+	// RateLimiter paces requests sent to the service.
+	// Requests are not limited if RateLimiter is nil.
+	RateLimiter *RateLimiter
+
+	// Concurrency is the maximum number of requests
+	// sent at the same time by bulk methods.
+	Concurrency int
+
+	// ParseMode controls how csv records that can't be parsed are
+	// handled. In lenient mode they are skipped and reported in
+	// TimeSeries.Rejected, GroupReadings.Rejected and
+	// StationLevels.Rejected.
+	ParseMode ParseMode
+
+	// Location is the time zone of timestamps in csv files
+	// published by the service. If nil, DefaultLocation is used.
+	Location *time.Location
+
+	// Log reports retries and other events worth knowing about.
+	// Nothing is logged if Log is nil.
+	Log *log.Logger
 }
 
-// NewClient knows how to construct a new default rivers client.
+// NewClient knows how to construct a new rivers client.
 // The client will be used to retrieve information about
 // various measures recorded by sensors.
-func NewClient() *Client {
-	return &Client{
+//
+// Without options the client uses default settings.
+// It errors if any of the options is not valid.
+func NewClient(opts ...ClientOption) (*Client, error) {
+	c := Client{
 		UserAgent: "Rivers/" + libVersion,
-		BaseURL:   "http://waterlevel.ie",
+		BaseURL:   "https://waterlevel.ie",
 		HTTPClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		Retry:       DefaultRetryPolicy(),
+		RateLimiter: NewRateLimiter(10, 10),
+		Concurrency: 4,
+		Location:    DefaultLocation(),
+	}
+	for _, opt := range opts {
+		if err := opt(&c); err != nil {
+			return nil, fmt.Errorf("creating client: %w", err)
+		}
+	}
+	return &c, nil
+}
+
+// logf is a helper func that writes to the client logger, if set.
+func (c *Client) logf(format string, v ...any) {
+	if c.Log == nil {
+		return
 	}
+	c.Log.Printf(format, v...)
 }
 
 // GetLatestWaterLevels returns latest water level readings from sensors.
@@ -118,9 +285,9 @@ func (c *Client) GetLatestWaterLevels(ctx context.Context) ([]StationWaterLevelR
 			continue
 		}
 
-		stationID, err := fromStrToInt(p.Properties.StationRef)
+		stationID, err := ParseStationRef(p.Properties.StationRef)
 		if err != nil {
-			return nil, fmt.Errorf("converting station id from string to int: %w", err)
+			return nil, fmt.Errorf("parsing station reference: %w", err)
 		}
 
 		t, err := time.Parse(time.RFC3339, p.Properties.Datetime)
@@ -128,7 +295,7 @@ func (c *Client) GetLatestWaterLevels(ctx context.Context) ([]StationWaterLevelR
 			return nil, fmt.Errorf("parsing reading time: %w", err)
 		}
 
-		wl, err := toMillimeters(p.Properties.Value)
+		wl, err := ParseLevel(p.Properties.Value)
 		if err != nil {
 			return nil, err
 		}
@@ -137,147 +304,396 @@ func (c *Client) GetLatestWaterLevels(ctx context.Context) ([]StationWaterLevelR
 			Name:       p.Properties.StationName,
 			Readtime:   t,
 			WaterLevel: wl,
+			Sensor:     SensorLevel,
+			ErrCode:    p.Properties.ErrCode,
 		}
 		readings = append(readings, reading)
 	}
 	return readings, nil
 }
 
-// GetDayLevel knows how to return water level readings recorded for
-// last 24hr period for the given stationID number.
-func (c *Client) GetDayLevel(ctx context.Context, stationID string) ([]WaterLevelReading, error) {
-	url, err := c.urlLevel(stationID, "day")
+// GetLatestReadings returns latest readings from all sensors
+// installed in stations, regardless of the sensor kind.
+func (c *Client) GetLatestReadings(ctx context.Context) ([]SensorReading, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/geojson/latest", c.BaseURL), nil)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
+	var res response
+	if err := c.sendRequestJSON(req, &res); err != nil {
 		return nil, err
 	}
-	return c.requestWaterLevelCSV(req)
+
+	readings := make([]SensorReading, 0, len(res.Features))
+	for _, p := range res.Features {
+		t, err := time.Parse(time.RFC3339, p.Properties.Datetime)
+		if err != nil {
+			return nil, fmt.Errorf("parsing reading time: %w", err)
+		}
+		stationID, err := ParseStationRef(p.Properties.StationRef)
+		if err != nil {
+			return nil, fmt.Errorf("parsing station reference: %w", err)
+		}
+		v, err := strconv.ParseFloat(p.Properties.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing value for station %q sensor %q: %w", p.Properties.StationRef, p.Properties.SensorRef, err)
+		}
+		reading := SensorReading{
+			StationID:   stationID,
+			StationName: p.Properties.StationName,
+			SensorID:    p.Properties.SensorRef,
+			Kind:        parseSensorKind(p.Properties.SensorRef),
+			RegionID:    p.Properties.RegionID,
+			Value:       v,
+			Timestamp:   t,
+			ErrCode:     p.Properties.ErrCode,
+		}
+		readings = append(readings, reading)
+	}
+	return readings, nil
 }
 
-// GetWeekLevel knows how to return water level readings recorded for
-// last week period for the given stationID number.
-func (c *Client) GetWeekLevel(ctx context.Context, stationID string) ([]WaterLevelReading, error) {
-	url, err := c.urlLevel(stationID, "week")
+// GetStations returns the catalogue of stations published by the
+// water level service. Coordinates in the GeoJSON feed are in
+// [long, lat] order.
+//
+// The stations feed does not carry regions, so they are joined
+// from the latest readings feed. The RegionID is left zero for
+// stations that have no readings in the latest feed.
+func (c *Client) GetStations(ctx context.Context) ([]Station, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/geojson/", c.BaseURL), nil)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	var res stationsResponse
+	if err := c.sendRequestJSON(req, &res); err != nil {
+		return nil, err
+	}
+	stations, err := parseStations(res)
 	if err != nil {
 		return nil, err
 	}
-	return c.requestWaterLevelCSV(req)
+
+	regions, err := c.getStationRegions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving station regions: %w", err)
+	}
+	for i, s := range stations {
+		if s.RegionID == 0 {
+			stations[i].RegionID = regions[s.ID]
+		}
+	}
+	return stations, nil
 }
 
-// GetMonthLevel knows how to return water level readings recorded for
-// last 4 weeks period for the given stationID number.
-func (c *Client) GetMonthLevel(ctx context.Context, stationID string) ([]WaterLevelReading, error) {
-	url, err := c.urlLevel(stationID, "month")
+// getStationRegions returns regions of stations
+// found in the latest readings feed.
+func (c *Client) getStationRegions(ctx context.Context) (map[StationRef]int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/geojson/latest", c.BaseURL), nil)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
+	var res response
+	if err := c.sendRequestJSON(req, &res); err != nil {
 		return nil, err
 	}
-	return c.requestWaterLevelCSV(req)
+	regions := make(map[StationRef]int)
+	for _, p := range res.Features {
+		ref, err := ParseStationRef(p.Properties.StationRef)
+		if err != nil {
+			return nil, fmt.Errorf("parsing station reference: %w", err)
+		}
+		regions[ref] = p.Properties.RegionID
+	}
+	return regions, nil
 }
 
-// GetDayTemperature knows how to return water temperature
-// recorded for last 24hr period for the given stationID number.
-func (c *Client) GetDayTemperature(ctx context.Context, stationID string) ([]WaterTemperatureReading, error) {
-	url, err := c.urlTemperature(stationID, "day")
+func parseStations(res stationsResponse) ([]Station, error) {
+	stations := make([]Station, 0, len(res.Features))
+	for _, f := range res.Features {
+		if len(f.Geometry.Coordinates) != 2 {
+			return nil, fmt.Errorf("parsing coordinates for station %q: invalid point %v", f.Properties.Ref, f.Geometry.Coordinates)
+		}
+		ref, err := ParseStationRef(f.Properties.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("parsing station reference: %w", err)
+		}
+		station := Station{
+			ID: ref,
+			// Some station names in the feed come with trailing spaces, so trim them.
+			Name:     strings.TrimSpace(f.Properties.Name),
+			RegionID: f.Properties.RegionID,
+			Long:     f.Geometry.Coordinates[0],
+			Lat:      f.Geometry.Coordinates[1],
+		}
+		stations = append(stations, station)
+	}
+	return stations, nil
+}
+
+// Period represents a time period for which the water level
+// service keeps sensor readings.
+type Period string
+
+const (
+	// PeriodDay represents last 24hr period.
+	PeriodDay Period = "day"
+	// PeriodWeek represents last week period.
+	PeriodWeek Period = "week"
+	// PeriodMonth represents last 4 weeks period.
+	PeriodMonth Period = "month"
+)
+
+var validPeriods = []Period{PeriodDay, PeriodWeek, PeriodMonth}
+
+// GetHistory knows how to return readings recorded by the given sensor
+// kind installed in the given station.
+// The period determines how far back the readings go.
+// The sensor should be one of SensorLevel, SensorTemperature
+// or SensorVoltage.
+func (c *Client) GetHistory(ctx context.Context, stationID StationRef, sensor SensorKind, period Period) (TimeSeries, error) {
+	url, err := c.urlHistory(stationID, sensor, period)
 	if err != nil {
-		return nil, err
+		return TimeSeries{}, err
 	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, err
+		return TimeSeries{}, err
 	}
-	return c.requestWaterTemperatureCSV(req)
+	readings, rejected, err := c.requestSensorCSV(req)
+	if err != nil {
+		return TimeSeries{}, withStation(err, stationID, sensor)
+	}
+	return TimeSeries{
+		StationID: stationID,
+		Sensor:    sensor,
+		Period:    period,
+		Readings:  readings,
+		Rejected:  rejected,
+	}, nil
+}
+
+// GetDayLevel knows how to return water level readings recorded for
+// last 24hr period for the given station.
+func (c *Client) GetDayLevel(ctx context.Context, stationID StationRef) ([]WaterLevelReading, error) {
+	readings, _, err := c.getLevelHistory(ctx, stationID, PeriodDay)
+	return readings, err
+}
+
+// GetWeekLevel knows how to return water level readings recorded for
+// last week period for the given station.
+func (c *Client) GetWeekLevel(ctx context.Context, stationID StationRef) ([]WaterLevelReading, error) {
+	readings, _, err := c.getLevelHistory(ctx, stationID, PeriodWeek)
+	return readings, err
+}
+
+// GetMonthLevel knows how to return water level readings recorded for
+// last 4 weeks period for the given station.
+func (c *Client) GetMonthLevel(ctx context.Context, stationID StationRef) ([]WaterLevelReading, error) {
+	readings, _, err := c.getLevelHistory(ctx, stationID, PeriodMonth)
+	return readings, err
+}
+
+// GetDayTemperature knows how to return water temperature
+// recorded for last 24hr period for the given station.
+func (c *Client) GetDayTemperature(ctx context.Context, stationID StationRef) ([]WaterTemperatureReading, error) {
+	readings, _, err := c.getTemperatureHistory(ctx, stationID, PeriodDay)
+	return readings, err
 }
 
 // GetWeekTemperature knows how to return water temperature
-// recorded for last week period for the given stationID number.
-func (c *Client) GetWeekTemperature(ctx context.Context, stationID string) ([]WaterTemperatureReading, error) {
-	url, err := c.urlTemperature(stationID, "week")
+// recorded for last week period for the given station.
+func (c *Client) GetWeekTemperature(ctx context.Context, stationID StationRef) ([]WaterTemperatureReading, error) {
+	readings, _, err := c.getTemperatureHistory(ctx, stationID, PeriodWeek)
+	return readings, err
+}
+
+// GetMonthTemperature knows how to return water temperature
+// recorded for last 4 weeks period for the given station.
+func (c *Client) GetMonthTemperature(ctx context.Context, stationID StationRef) ([]WaterTemperatureReading, error) {
+	readings, _, err := c.getTemperatureHistory(ctx, stationID, PeriodMonth)
+	return readings, err
+}
+
+// GetDayVoltage knows how to return gauge voltage
+// recorded for last 24hr period for the given station.
+func (c *Client) GetDayVoltage(ctx context.Context, stationID StationRef) ([]VoltageReading, error) {
+	readings, _, err := c.getVoltageHistory(ctx, stationID, PeriodDay)
+	return readings, err
+}
+
+// GetWeekVoltage knows how to return gauge voltage
+// recorded for last week period for the given station.
+func (c *Client) GetWeekVoltage(ctx context.Context, stationID StationRef) ([]VoltageReading, error) {
+	readings, _, err := c.getVoltageHistory(ctx, stationID, PeriodWeek)
+	return readings, err
+}
+
+// GetMonthVoltage knows how to return gauge voltage
+// recorded for last 4 weeks period for the given station.
+func (c *Client) GetMonthVoltage(ctx context.Context, stationID StationRef) ([]VoltageReading, error) {
+	readings, _, err := c.getVoltageHistory(ctx, stationID, PeriodMonth)
+	return readings, err
+}
+
+func (c *Client) getLevelHistory(ctx context.Context, stationID StationRef, period Period) ([]WaterLevelReading, []RejectedRow, error) {
+	ts, err := c.GetHistory(ctx, stationID, SensorLevel, period)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
+	levels := make([]WaterLevelReading, 0, len(ts.Readings))
+	for _, r := range ts.Readings {
+		levels = append(levels, WaterLevelReading{
+			Timestamp: r.Timestamp,
+			Value:     LevelFromMeters(r.Value),
+		})
 	}
-	return c.requestWaterTemperatureCSV(req)
+	return levels, ts.Rejected, nil
 }
 
-// GetMonthTemperature knows how to return water temperature
-// recorded for last 4 weeks period for the given stationID number.
-func (c *Client) GetMonthTemperature(ctx context.Context, stationID string) ([]WaterTemperatureReading, error) {
-	url, err := c.urlTemperature(stationID, "month")
+func (c *Client) getTemperatureHistory(ctx context.Context, stationID StationRef, period Period) ([]WaterTemperatureReading, []RejectedRow, error) {
+	ts, err := c.GetHistory(ctx, stationID, SensorTemperature, period)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	temps := make([]WaterTemperatureReading, 0, len(ts.Readings))
+	for _, r := range ts.Readings {
+		temps = append(temps, WaterTemperatureReading{
+			Timestamp: r.Timestamp,
+			Value:     r.Value,
+		})
+	}
+	return temps, ts.Rejected, nil
+}
+
+func (c *Client) getVoltageHistory(ctx context.Context, stationID StationRef, period Period) ([]VoltageReading, []RejectedRow, error) {
+	ts, err := c.GetHistory(ctx, stationID, SensorVoltage, period)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return c.requestWaterTemperatureCSV(req)
+	voltages := make([]VoltageReading, 0, len(ts.Readings))
+	for _, r := range ts.Readings {
+		voltages = append(voltages, VoltageReading{
+			Timestamp: r.Timestamp,
+			Value:     r.Value,
+		})
+	}
+	return voltages, ts.Rejected, nil
 }
 
 // GetGroupWaterLevel returns water level readings for
 // stations that belong to the given groupID.
 //
-// The value of roupID should be between 1 and 28.
-func (c *Client) GetGroupWaterLevel(ctx context.Context, groupID int) ([]StationWaterLevelReading, error) {
-	if groupID < 1 || groupID > 28 {
-		return nil, fmt.Errorf("invalid groupID %d, expecting value between 1 and 28", groupID)
-	}
-	url := fmt.Sprintf("%s/data/group/group_%d.csv", c.BaseURL, groupID)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// Station names found in the group csv file are resolved to station
+// references using the bundled station catalogue. The StationID is
+// left zero for stations that can't be resolved.
+//
+// The value of groupID should be one of the IDs returned by Groups.
+// It errors with ErrGroupNotFound if the group does not exist.
+func (c *Client) GetGroupWaterLevel(ctx context.Context, groupID int) ([]StationGroupReading, error) {
+	gr, err := c.GetGroupReadings(ctx, groupID)
 	if err != nil {
 		return nil, err
 	}
+	return gr.Readings, nil
+}
 
-	groupReadings, err := c.sendStationGroupRequestCSV(req)
+// GetGroupWaterLevelByName returns water level readings for
+// stations that belong to the group with the given name,
+// for example "Nore" or "Shannon". Names are case-insensitive.
+// It errors with ErrGroupNotFound if the group does not exist.
+func (c *Client) GetGroupWaterLevelByName(ctx context.Context, name string) ([]StationGroupReading, error) {
+	cat, err := loadCatalogue()
 	if err != nil {
 		return nil, err
 	}
+	group, err := cat.groupByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid group name: %w", err)
+	}
+	readings, _, err := c.getGroupWaterLevel(ctx, group)
+	return readings, err
+}
 
-	var readings []StationWaterLevelReading
+// GetGroupReadings is like GetGroupWaterLevel, but it also returns
+// values rejected in lenient mode, including values of stations
+// that have not reported yet, which are rejected with ErrMissingValue.
+func (c *Client) GetGroupReadings(ctx context.Context, groupID int) (GroupReadings, error) {
+	cat, err := loadCatalogue()
+	if err != nil {
+		return GroupReadings{}, err
+	}
+	group, err := cat.group(groupID)
+	if err != nil {
+		return GroupReadings{}, fmt.Errorf("invalid groupID: %w", err)
+	}
+	readings, rejected, err := c.getGroupWaterLevel(ctx, group)
+	if err != nil {
+		return GroupReadings{}, err
+	}
+	return GroupReadings{
+		Group:    group,
+		Readings: readings,
+		Rejected: rejected,
+	}, nil
+}
+
+func (c *Client) getGroupWaterLevel(ctx context.Context, group Group) ([]StationGroupReading, []RejectedRow, error) {
+	url := fmt.Sprintf("%s/data/group/group_%d.csv", c.BaseURL, group.ID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	groupReadings, rejected, err := c.sendStationGroupRequestCSV(req)
+	if err != nil {
+		return nil, nil, withGroup(err, group.ID)
+	}
+
+	var readings []StationGroupReading
 	for _, reading := range groupReadings {
-		station := StationWaterLevelReading{
-			Name:       reading.Name,
-			Readtime:   reading.Timestamp,
-			WaterLevel: reading.Value,
+		station := StationGroupReading{
+			GroupID:      group.ID,
+			GroupName:    group.Name,
+			StationID:    reading.RefID,
+			Name:         reading.Name,
+			Readtime:     reading.Timestamp,
+			ReadingValue: reading.Value,
 		}
 		readings = append(readings, station)
 	}
-	return readings, nil
+	return readings, rejected, nil
 }
 
-var validPeriods = []string{"day", "week", "month"}
-
-// urlLevel takes stationid and time period and builds a valid url.
-// If the period is not valid it errors. Period value should be
-// one of 'day', 'week' or 'month'.
-func (c *Client) urlLevel(stationID, period string) (string, error) {
-	if !slices.Contains(validPeriods, period) {
-		return "", fmt.Errorf("invalid period %q, expecting one of 'day', 'week', 'month'", period)
-	}
-	return fmt.Sprintf("%s/data/%s/%s_000%v.csv", c.BaseURL, period, stationID, levelSensor), nil
+// sensorRefs maps sensor kinds to sensor references
+// used in the GeoJSON feed.
+var sensorRefs = map[SensorKind]string{
+	SensorLevel:       sensorTypeLevel,
+	SensorTemperature: sensorTypeTemp,
+	SensorVoltage:     sensorTypeVoltage,
+	SensorLevelOD:     sensorTypeLevelOD,
 }
 
-// urlTemperature takes stationid and time period and builds a valid url.
-// If the period is not valid it errors. Period value should be
-// one of 'day', 'week' or 'month'.
-func (c *Client) urlTemperature(stationID, period string) (string, error) {
+// historySensors holds sensor kinds the service publishes csv data
+// files for. Readings of the level_od sensor are only available
+// in the GeoJSON feed.
+var historySensors = []SensorKind{SensorLevel, SensorTemperature, SensorVoltage}
+
+// urlHistory takes station reference, sensor kind and time period and
+// builds a valid url. If the station reference, the period or the sensor
+// kind is not valid it errors.
+// Period value should be one of 'day', 'week' or 'month'.
+func (c *Client) urlHistory(stationID StationRef, sensor SensorKind, period Period) (string, error) {
+	if err := stationID.validate(); err != nil {
+		return "", err
+	}
 	if !slices.Contains(validPeriods, period) {
 		return "", fmt.Errorf("invalid period %q, expecting one of 'day', 'week', 'month'", period)
 	}
-	return fmt.Sprintf("%s/data/%s/%s_000%v.csv", c.BaseURL, period, stationID, tempSensor), nil
+	if !slices.Contains(historySensors, sensor) {
+		return "", fmt.Errorf("invalid sensor %q, expecting one of 'level', 'temperature', 'voltage'", sensor)
+	}
+	return fmt.Sprintf("%s/data/%s/%s_%s.csv", c.BaseURL, period, stationID, sensorRefs[sensor]), nil
 }
 
 func (c *Client) sendRequestJSON(req *http.Request, v any) error {
@@ -285,117 +701,87 @@ func (c *Client) sendRequestJSON(req *http.Request, v any) error {
 	req.Header.Set("Accept", "application/json; charset=utf-8")
 	req.Header.Set("User-Agent", c.UserAgent)
 
-	res, err := c.sendRequestWithBackoff(req)
+	res, err := c.sendRequest(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
-		var errRes errResponse
-		if err := json.NewDecoder(res.Body).Decode(&errRes); err != nil {
-			return errors.New(errRes.Message)
-		}
-		return fmt.Errorf("unknown error, status code: %d", res.StatusCode)
+	if err := checkResponseStatusCode(res); err != nil {
+		return err
 	}
 	return json.NewDecoder(res.Body).Decode(&v)
 }
 
-func (c *Client) sendRequestWithBackoff(req *http.Request) (*http.Response, error) {
-	res, err := c.HTTPClient.Do(req)
-	base := time.Second
-	cap := time.Minute
-	for backoff := base; err != nil; backoff <<= 1 {
-		if backoff > cap {
-			backoff = cap
-		}
-		jitter := rand.Int63n(int64(backoff * 3))
-		time.Sleep(base + time.Duration(jitter))
-		res, err = c.HTTPClient.Do(req)
-	}
-	return res, err
-}
-
-func (c *Client) requestWaterLevelCSV(req *http.Request) ([]WaterLevelReading, error) {
+func (c *Client) requestSensorCSV(req *http.Request) ([]Reading, []RejectedRow, error) {
 	req.Header.Set("Content-Type", "text/csv")
 	req.Header.Set("Accept", "text/csv")
 	req.Header.Set("User-Agent", c.UserAgent)
 
-	res, err := c.sendRequestWithBackoff(req)
+	res, err := c.sendRequest(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer res.Body.Close()
 
 	if err := checkResponseStatusCode(res); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return ReadWaterLevelCSV(res.Body)
-}
-
-func (c *Client) requestWaterTemperatureCSV(req *http.Request) ([]WaterTemperatureReading, error) {
-	req.Header.Set("Content-Type", "text/csv")
-	req.Header.Set("Accept", "text/csv")
-	req.Header.Set("User-Agent", c.UserAgent)
-
-	res, err := c.sendRequestWithBackoff(req)
+	s := NewSensorScanner(res.Body)
+	s.Mode = c.ParseMode
+	s.Location = c.Location
+	readings, err := collect(s)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer res.Body.Close()
-
-	if err := checkResponseStatusCode(res); err != nil {
-		return nil, err
+	for _, r := range s.Rejected() {
+		c.logf("rivers: rejected csv record on line %d from %s: %v", r.Line, req.URL, r.Err)
 	}
-	return ReadWaterTemperatureCSV(res.Body)
+	return readings, s.Rejected(), nil
 }
 
-func (c *Client) sendStationGroupRequestCSV(req *http.Request) ([]WaterLevelReading, error) {
+func (c *Client) sendStationGroupRequestCSV(req *http.Request) ([]WaterLevelReading, []RejectedRow, error) {
 	req.Header.Set("Content-Type", "text/csv")
 	req.Header.Set("Accept", "text/csv")
 	req.Header.Set("User-Agent", c.UserAgent)
 
-	res, err := c.sendRequestWithBackoff(req)
+	res, err := c.sendRequest(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer res.Body.Close()
 
 	if err := checkResponseStatusCode(res); err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	s := NewGroupScanner(res.Body)
+	s.Mode = c.ParseMode
+	s.Location = c.Location
+	readings, err := readGroup(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, r := range s.Rejected() {
+		// Stations that have not reported yet leave
+		// their values empty, that's not worth logging.
+		if errors.Is(r.Err, ErrMissingValue) {
+			continue
+		}
+		c.logf("rivers: rejected value for %q on line %d from %s: %v", r.Column, r.Line, req.URL, r.Err)
 	}
-	return ReadGroupCSV(res.Body)
+	return readings, s.Rejected(), nil
 }
 
-// checkResponseStatusCode takes http Respopnse and validates
+// checkResponseStatusCode takes http Response and validates
 // HTTP response status code.
-// It errors if the status code is not 2xx or 3xx.
+// It errors with *APIError if the status code is not 2xx or 3xx.
 func checkResponseStatusCode(res *http.Response) error {
 	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
-		return fmt.Errorf("unknown error, status code: %d", res.StatusCode)
+		return newAPIError(res)
 	}
 	return nil
 }
 
-// toMillimeters is a helper func that takes a string representing a float value
-// of water level sensor reading in meters and converts it to integer representing
-// water level in millimeters.
-// It errors if the input string does not represent float value.
-func toMillimeters(s string) (int, error) {
-	v, err := strconv.ParseFloat(s, 64)
-	if err != nil {
-		return 0, fmt.Errorf("convering to millimeters: %w", err)
-	}
-	return int(v * 1000), nil
-}
-
-// fromStrToInt is a helper func that takes a string representing
-// stationID and returns stationID as int (with trimmed leading zeros).
-func fromStrToInt(s string) (int, error) {
-	st := strings.TrimLeft(s, "0")
-	return strconv.Atoi(st)
-}
-
 func writeReadingsTo(w io.Writer, readings []StationWaterLevelReading) {
 	for _, reading := range readings {
 		fmt.Fprintf(w, "time: %s, station: %s, id: %d, level: %d\n",
@@ -403,18 +789,56 @@ func writeReadingsTo(w io.Writer, readings []StationWaterLevelReading) {
 	}
 }
 
+// newCLICache creates a disk cache in the user cache directory.
+func newCLICache() (*HTTPCache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	store, err := NewDiskCache(filepath.Join(dir, "rivers"))
+	if err != nil {
+		return nil, err
+	}
+	return &HTTPCache{
+		Store:        store,
+		TTL:          time.Minute,
+		StaleIfError: time.Hour,
+	}, nil
+}
+
 // GetLatestWaterLevels returns latests readings from all stations.
 //
 // This func uses default rivers' client under the hood.
 func GetLatestWaterLevels(ctx context.Context) ([]StationWaterLevelReading, error) {
-	return NewClient().GetLatestWaterLevels(ctx)
+	c, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+	return c.GetLatestWaterLevels(ctx)
 }
 
 // RunCLI executes program and prints out latest recorded water levels.
+//
+// Responses are cached in the user cache directory, so running
+// the program repeatedly does not download the same data again.
 func RunCLI() {
 	ctx, shutdown := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer shutdown()
-	readings, err := GetLatestWaterLevels(ctx)
+	var opts []ClientOption
+	cache, err := newCLICache()
+	if err != nil {
+		// The program works without the cache, it only
+		// downloads the data every time it runs.
+		fmt.Fprintf(os.Stderr, "rivers: response cache disabled: %v\n", err)
+	} else {
+		opts = append(opts, WithCache(cache))
+	}
+	client, err := NewClient(opts...)
+	if err != nil {
+		fmt.Fprint(os.Stderr, err)
+		os.Exit(1)
+	}
+	readings, err := client.GetLatestWaterLevels(ctx)
 	if err != nil {
 		fmt.Fprint(os.Stderr, err)
 		os.Exit(1)
@@ -0,0 +1,162 @@
+package rivers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ClientOption configures the Client created by NewClient.
+type ClientOption func(*Client) error
+
+// WithBaseURL sets the URL of the water level service,
+// for example the URL of a mirror.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		u, err := validateBaseURL(baseURL)
+		if err != nil {
+			return fmt.Errorf("setting up base URL: %w", err)
+		}
+		c.BaseURL = u
+		return nil
+	}
+}
+
+// WithFallbackURLs sets base URLs tried in the given order
+// when the base URL endpoint fails.
+func WithFallbackURLs(urls ...string) ClientOption {
+	return func(c *Client) error {
+		fallbacks := make([]string, 0, len(urls))
+		for _, fallback := range urls {
+			u, err := validateBaseURL(fallback)
+			if err != nil {
+				return fmt.Errorf("setting up fallback URL: %w", err)
+			}
+			fallbacks = append(fallbacks, u)
+		}
+		c.FallbackURLs = fallbacks
+		return nil
+	}
+}
+
+// validateBaseURL checks that the base URL is an absolute
+// http or https URL and returns it without the trailing slash.
+func validateBaseURL(baseURL string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid scheme %q, expecting 'http' or 'https'", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("missing host in %q", baseURL)
+	}
+	return strings.TrimRight(baseURL, "/"), nil
+}
+
+// WithHTTPClient sets the HTTP client used to send requests.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) error {
+		if hc == nil {
+			return errors.New("setting up HTTP client: nil client")
+		}
+		c.HTTPClient = hc
+		return nil
+	}
+}
+
+// WithTransport sets the transport used by the HTTP client,
+// for example to instrument requests.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) error {
+		if rt == nil {
+			return errors.New("setting up HTTP transport: nil transport")
+		}
+		hc := *c.HTTPClient
+		hc.Transport = rt
+		c.HTTPClient = &hc
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with requests.
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) error {
+		if ua == "" {
+			return errors.New("setting up user agent: empty user agent")
+		}
+		c.UserAgent = ua
+		return nil
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry failed requests.
+func WithRetryPolicy(p RetryPolicy) ClientOption {
+	return func(c *Client) error {
+		c.Retry = p
+		return nil
+	}
+}
+
+// WithCache sets the response cache.
+func WithCache(hc *HTTPCache) ClientOption {
+	return func(c *Client) error {
+		c.Cache = hc
+		return nil
+	}
+}
+
+// WithRateLimiter sets the limiter pacing requests. Passing nil
+// disables rate limiting.
+func WithRateLimiter(l *RateLimiter) ClientOption {
+	return func(c *Client) error {
+		c.RateLimiter = l
+		return nil
+	}
+}
+
+// WithConcurrency sets the maximum number of requests
+// sent at the same time by bulk methods.
+func WithConcurrency(n int) ClientOption {
+	return func(c *Client) error {
+		if n < 1 {
+			return fmt.Errorf("setting up concurrency: invalid value %d, expecting value greater than 0", n)
+		}
+		c.Concurrency = n
+		return nil
+	}
+}
+
+// WithParseMode sets how csv records that can't be parsed are handled.
+func WithParseMode(m ParseMode) ClientOption {
+	return func(c *Client) error {
+		c.ParseMode = m
+		return nil
+	}
+}
+
+// WithLocation sets the time zone of timestamps in csv files
+// published by the service. It errors if the location is nil.
+func WithLocation(loc *time.Location) ClientOption {
+	return func(c *Client) error {
+		if loc == nil {
+			return errors.New("setting up location: nil location")
+		}
+		c.Location = loc
+		return nil
+	}
+}
+
+// WithClientLogger sets the logger used to report retries
+// and other events worth knowing about.
+func WithClientLogger(l *log.Logger) ClientOption {
+	return func(c *Client) error {
+		c.Log = l
+		return nil
+	}
+}
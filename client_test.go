@@ -2,6 +2,7 @@ package rivers_test
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -17,8 +18,7 @@ func TestRiversClient_GetsLatestWaterLevelReadings(t *testing.T) {
 	t.Parallel()
 	ts := newTestServer("/geojson/latest", "testdata/latest_short.json", t)
 
-	client := rivers.NewClient()
-	client.BaseURL = ts.URL
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
 
 	got, err := client.GetLatestWaterLevels(context.Background())
 	if err != nil {
@@ -31,6 +31,8 @@ func TestRiversClient_GetsLatestWaterLevelReadings(t *testing.T) {
 			Name:       "Sandy Mills",
 			Readtime:   time.Date(2021, 02, 18, 06, 00, 00, 00, time.UTC),
 			WaterLevel: 1715,
+			Sensor:     rivers.SensorLevel,
+			ErrCode:    99,
 		},
 	}
 
@@ -39,36 +41,178 @@ func TestRiversClient_GetsLatestWaterLevelReadings(t *testing.T) {
 	}
 }
 
+func TestRiversClient_GetsLatestReadingsForAllSensors(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer("/geojson/latest", "testdata/latest_short.json", t)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+
+	got, err := client.GetLatestReadings(context.Background())
+	if err != nil {
+		t.Fatalf("GetLatestReadings() got error %v", err)
+	}
+
+	readtime := time.Date(2021, 02, 18, 06, 00, 00, 00, time.UTC)
+	want := []rivers.SensorReading{
+		{
+			StationID:   1041,
+			StationName: "Sandy Mills",
+			SensorID:    "0001",
+			Kind:        rivers.SensorLevel,
+			RegionID:    3,
+			Value:       1.715,
+			Timestamp:   readtime,
+			ErrCode:     99,
+		},
+		{
+			StationID:   1041,
+			StationName: "Sandy Mills",
+			SensorID:    "0002",
+			Kind:        rivers.SensorTemperature,
+			RegionID:    3,
+			Value:       4.8,
+			Timestamp:   readtime,
+			ErrCode:     99,
+		},
+		{
+			StationID:   1041,
+			StationName: "Sandy Mills",
+			SensorID:    "0003",
+			Kind:        rivers.SensorVoltage,
+			RegionID:    3,
+			Value:       13,
+			Timestamp:   readtime,
+			ErrCode:     99,
+		},
+		{
+			StationID:   1041,
+			StationName: "Sandy Mills",
+			SensorID:    "OD",
+			Kind:        rivers.SensorLevelOD,
+			RegionID:    3,
+			Value:       8.06,
+			Timestamp:   readtime,
+			ErrCode:     99,
+		},
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestRiversClient_GetsStations(t *testing.T) {
+	t.Parallel()
+	ts := newStationsTestServer("testdata/stations_short.json", "testdata/latest_short.json", t)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+
+	got, err := client.GetStations(context.Background())
+	if err != nil {
+		t.Fatalf("GetStations() got error %v", err)
+	}
+
+	// Ballybofey has no readings in the latest feed,
+	// so its region is unknown.
+	want := []rivers.Station{
+		{
+			ID:       1041,
+			Name:     "Sandy Mills",
+			RegionID: 3,
+			Lat:      54.838318,
+			Long:     -7.575758,
+		},
+		{
+			ID:   1043,
+			Name: "Ballybofey",
+			Lat:  54.799769,
+			Long: -7.790749,
+		},
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestRiversClient_GetsAllStationsFromCatalogue(t *testing.T) {
+	t.Parallel()
+	ts := newStationsTestServer("testdata/stations.json", "testdata/latest.json", t)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+
+	got, err := client.GetStations(context.Background())
+	if err != nil {
+		t.Fatalf("GetStations() got error %v", err)
+	}
+	if len(got) == 0 {
+		t.Fatal("want stations, got none")
+	}
+	withRegion := 0
+	for _, s := range got {
+		if s.ID.IsZero() || s.Name == "" {
+			t.Errorf("want station with id and name, got %+v", s)
+		}
+		if s.RegionID != 0 {
+			withRegion++
+		}
+	}
+	if withRegion == 0 {
+		t.Error("want stations with regions joined from the latest feed")
+	}
+}
+
+func TestRiversClient_GetStationsErrorsWhenLatestFeedFails(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/geojson/", func(rw http.ResponseWriter, r *http.Request) {
+		http.ServeFile(rw, r, "testdata/stations_short.json")
+	})
+	mux.HandleFunc("/geojson/latest", func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	client := newTestClient(t,
+		rivers.WithBaseURL(ts.URL),
+		rivers.WithRetryPolicy(rivers.NoRetryPolicy()),
+	)
+	_, err := client.GetStations(context.Background())
+	if !errors.Is(err, rivers.ErrServiceUnavailable) {
+		t.Errorf("want ErrServiceUnavailable, got %v", err)
+	}
+}
+
 func TestRiversClient_GetsDayWaterLevels(t *testing.T) {
 	t.Parallel()
 	ts := newTestServer("/data/day", "testdata/day_01041_0001.csv", t)
 
-	client := rivers.NewClient()
-	client.BaseURL = ts.URL
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
 
 	want := []rivers.WaterLevelReading{
 		{
-			Timestamp: time.Date(2021, 07, 10, 00, 00, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 10, 00, 00, 00, 00, dublin),
 			Value:     294,
 		},
 		{
-			Timestamp: time.Date(2021, 07, 10, 00, 15, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 10, 00, 15, 00, 00, dublin),
 			Value:     293,
 		},
 		{
-			Timestamp: time.Date(2021, 07, 10, 00, 30, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 10, 00, 30, 00, 00, dublin),
 			Value:     293,
 		},
 		{
-			Timestamp: time.Date(2021, 07, 10, 00, 45, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 10, 00, 45, 00, 00, dublin),
 			Value:     293,
 		},
 	}
 
-	stationID := "010104"
+	stationID := rivers.StationRef(10104)
 	got, err := client.GetDayLevel(context.Background(), stationID)
 	if err != nil {
-		t.Fatalf("client.GetDayLevel(%q) got error %v", stationID, err)
+		t.Fatalf("client.GetDayLevel(%s) got error %v", stationID, err)
 	}
 
 	if !cmp.Equal(want, got) {
@@ -80,32 +224,31 @@ func TestRiversClient_GetsWeekWaterLevels(t *testing.T) {
 	t.Parallel()
 	ts := newTestServer("/data/week", "testdata/week_01041_0001.csv", t)
 
-	client := rivers.NewClient()
-	client.BaseURL = ts.URL
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
 
 	want := []rivers.WaterLevelReading{
 		{
-			Timestamp: time.Date(2021, 07, 10, 00, 00, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 10, 00, 00, 00, 00, dublin),
 			Value:     294,
 		},
 		{
-			Timestamp: time.Date(2021, 07, 10, 00, 15, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 10, 00, 15, 00, 00, dublin),
 			Value:     293,
 		},
 		{
-			Timestamp: time.Date(2021, 07, 10, 00, 30, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 10, 00, 30, 00, 00, dublin),
 			Value:     293,
 		},
 		{
-			Timestamp: time.Date(2021, 07, 10, 00, 45, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 10, 00, 45, 00, 00, dublin),
 			Value:     293,
 		},
 	}
 
-	stationID := "010104"
+	stationID := rivers.StationRef(10104)
 	got, err := client.GetWeekLevel(context.Background(), stationID)
 	if err != nil {
-		t.Fatalf("client.GetWeekLevel(%q) got error %v", stationID, err)
+		t.Fatalf("client.GetWeekLevel(%s) got error %v", stationID, err)
 	}
 
 	if !cmp.Equal(want, got) {
@@ -117,32 +260,31 @@ func TestRiversClient_GetsMonthWaterLevel(t *testing.T) {
 	t.Parallel()
 	ts := newTestServer("/data/month", "testdata/month_01041_0001.csv", t)
 
-	client := rivers.NewClient()
-	client.BaseURL = ts.URL
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
 
 	want := []rivers.WaterLevelReading{
 		{
-			Timestamp: time.Date(2021, 07, 10, 00, 00, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 10, 00, 00, 00, 00, dublin),
 			Value:     294,
 		},
 		{
-			Timestamp: time.Date(2021, 07, 10, 00, 15, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 10, 00, 15, 00, 00, dublin),
 			Value:     293,
 		},
 		{
-			Timestamp: time.Date(2021, 07, 10, 00, 30, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 10, 00, 30, 00, 00, dublin),
 			Value:     293,
 		},
 		{
-			Timestamp: time.Date(2021, 07, 10, 00, 45, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 10, 00, 45, 00, 00, dublin),
 			Value:     293,
 		},
 	}
 
-	stationID := "010104"
+	stationID := rivers.StationRef(10104)
 	got, err := client.GetMonthLevel(context.Background(), stationID)
 	if err != nil {
-		t.Fatalf("client.GetMonthLevel(%q) got error %v", stationID, err)
+		t.Fatalf("client.GetMonthLevel(%s) got error %v", stationID, err)
 	}
 
 	if !cmp.Equal(want, got) {
@@ -154,28 +296,27 @@ func TestRiversClient_GetsDayWaterTemperature(t *testing.T) {
 	t.Parallel()
 	ts := newTestServer("/data/day", "testdata/day_01041_0002.csv", t)
 
-	client := rivers.NewClient()
-	client.BaseURL = ts.URL
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
 
 	want := []rivers.WaterTemperatureReading{
 		{
-			Timestamp: time.Date(2021, 07, 15, 22, 00, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 15, 22, 00, 00, 00, dublin),
 			Value:     19.900,
 		},
 		{
-			Timestamp: time.Date(2021, 07, 15, 23, 00, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 15, 23, 00, 00, 00, dublin),
 			Value:     19.700,
 		},
 		{
-			Timestamp: time.Date(2021, 07, 16, 00, 00, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 16, 00, 00, 00, 00, dublin),
 			Value:     19.400,
 		},
 	}
 
-	stationID := "010104"
+	stationID := rivers.StationRef(10104)
 	got, err := client.GetDayTemperature(context.Background(), stationID)
 	if err != nil {
-		t.Fatalf("GetDayTemperature(%q) got error %v", stationID, err)
+		t.Fatalf("GetDayTemperature(%s) got error %v", stationID, err)
 	}
 
 	if !cmp.Equal(want, got) {
@@ -187,28 +328,27 @@ func TestRiversClient_GetsWeekWaterTemperature(t *testing.T) {
 	t.Parallel()
 	ts := newTestServer("/data/week", "testdata/week_01041_0002.csv", t)
 
-	client := rivers.NewClient()
-	client.BaseURL = ts.URL
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
 
 	want := []rivers.WaterTemperatureReading{
 		{
-			Timestamp: time.Date(2021, 07, 15, 22, 00, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 15, 22, 00, 00, 00, dublin),
 			Value:     19.900,
 		},
 		{
-			Timestamp: time.Date(2021, 07, 15, 23, 00, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 15, 23, 00, 00, 00, dublin),
 			Value:     19.700,
 		},
 		{
-			Timestamp: time.Date(2021, 07, 16, 00, 00, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 16, 00, 00, 00, 00, dublin),
 			Value:     19.400,
 		},
 	}
 
-	stationID := "010104"
+	stationID := rivers.StationRef(10104)
 	got, err := client.GetWeekTemperature(context.Background(), stationID)
 	if err != nil {
-		t.Fatalf("GetWeekTemperature(%q) got error %v", stationID, err)
+		t.Fatalf("GetWeekTemperature(%s) got error %v", stationID, err)
 	}
 
 	if !cmp.Equal(want, got) {
@@ -220,28 +360,123 @@ func TestRiversClient_GetsMonthWaterTemperature(t *testing.T) {
 	t.Parallel()
 	ts := newTestServer("/data/month", "testdata/month_01041_0002.csv", t)
 
-	client := rivers.NewClient()
-	client.BaseURL = ts.URL
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
 
 	want := []rivers.WaterTemperatureReading{
 		{
-			Timestamp: time.Date(2021, 07, 15, 22, 00, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 15, 22, 00, 00, 00, dublin),
 			Value:     19.900,
 		},
 		{
-			Timestamp: time.Date(2021, 07, 15, 23, 00, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 15, 23, 00, 00, 00, dublin),
 			Value:     19.700,
 		},
 		{
-			Timestamp: time.Date(2021, 07, 16, 00, 00, 00, 00, time.UTC),
+			Timestamp: time.Date(2021, 07, 16, 00, 00, 00, 00, dublin),
 			Value:     19.400,
 		},
 	}
 
-	stationID := "010104"
+	stationID := rivers.StationRef(10104)
 	got, err := client.GetMonthTemperature(context.Background(), stationID)
 	if err != nil {
-		t.Fatalf("GetMonthTemperature(%q) got error %v", stationID, err)
+		t.Fatalf("GetMonthTemperature(%s) got error %v", stationID, err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestRiversClient_GetsDayVoltage(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer("/data/day", "testdata/day_01041_0003.csv", t)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+
+	want := []rivers.VoltageReading{
+		{
+			Timestamp: time.Date(2021, 07, 15, 22, 00, 00, 00, dublin),
+			Value:     13.100,
+		},
+		{
+			Timestamp: time.Date(2021, 07, 15, 23, 00, 00, 00, dublin),
+			Value:     13.000,
+		},
+		{
+			Timestamp: time.Date(2021, 07, 16, 00, 00, 00, 00, dublin),
+			Value:     12.900,
+		},
+	}
+
+	stationID := rivers.StationRef(10104)
+	got, err := client.GetDayVoltage(context.Background(), stationID)
+	if err != nil {
+		t.Fatalf("GetDayVoltage(%s) got error %v", stationID, err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestRiversClient_GetsWeekVoltage(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer("/data/week", "testdata/week_01041_0003.csv", t)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+
+	want := []rivers.VoltageReading{
+		{
+			Timestamp: time.Date(2021, 07, 15, 22, 00, 00, 00, dublin),
+			Value:     13.100,
+		},
+		{
+			Timestamp: time.Date(2021, 07, 15, 23, 00, 00, 00, dublin),
+			Value:     13.000,
+		},
+		{
+			Timestamp: time.Date(2021, 07, 16, 00, 00, 00, 00, dublin),
+			Value:     12.900,
+		},
+	}
+
+	stationID := rivers.StationRef(10104)
+	got, err := client.GetWeekVoltage(context.Background(), stationID)
+	if err != nil {
+		t.Fatalf("GetWeekVoltage(%s) got error %v", stationID, err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestRiversClient_GetsMonthVoltage(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer("/data/month", "testdata/month_01041_0003.csv", t)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+
+	want := []rivers.VoltageReading{
+		{
+			Timestamp: time.Date(2021, 07, 15, 22, 00, 00, 00, dublin),
+			Value:     13.100,
+		},
+		{
+			Timestamp: time.Date(2021, 07, 15, 23, 00, 00, 00, dublin),
+			Value:     13.000,
+		},
+		{
+			Timestamp: time.Date(2021, 07, 16, 00, 00, 00, 00, dublin),
+			Value:     12.900,
+		},
+	}
+
+	stationID := rivers.StationRef(10104)
+	got, err := client.GetMonthVoltage(context.Background(), stationID)
+	if err != nil {
+		t.Fatalf("GetMonthVoltage(%s) got error %v", stationID, err)
 	}
 
 	if !cmp.Equal(want, got) {
@@ -249,33 +484,188 @@ func TestRiversClient_GetsMonthWaterTemperature(t *testing.T) {
 	}
 }
 
+func TestRiversClient_GetHistoryRequestsSensorFileForPeriod(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		wantPath := "/data/week/01041_0003.csv"
+		if r.URL.Path != wantPath {
+			t.Errorf("want request to %q, got %q", wantPath, r.URL.Path)
+		}
+		http.ServeFile(rw, r, "testdata/week_01041_0003.csv")
+	}))
+	t.Cleanup(ts.Close)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+
+	got, err := client.GetHistory(context.Background(), 1041, rivers.SensorVoltage, rivers.PeriodWeek)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := rivers.TimeSeries{
+		StationID: 1041,
+		Sensor:    rivers.SensorVoltage,
+		Period:    rivers.PeriodWeek,
+		Readings: []rivers.Reading{
+			{
+				Timestamp: time.Date(2021, 07, 15, 22, 00, 00, 00, dublin),
+				Value:     13.100,
+			},
+			{
+				Timestamp: time.Date(2021, 07, 15, 23, 00, 00, 00, dublin),
+				Value:     13.000,
+			},
+			{
+				Timestamp: time.Date(2021, 07, 16, 00, 00, 00, 00, dublin),
+				Value:     12.900,
+			},
+		},
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestRiversClient_GetHistoryReportsRejectedRecordsInLenientMode(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		io.WriteString(rw, "datetime,value\n2021-07-10 00:00,0.294\n2021-07-10 00:15,-\n2021-07-10 00:30,0.293\n")
+	}))
+	t.Cleanup(ts.Close)
+
+	client := newTestClient(t,
+		rivers.WithBaseURL(ts.URL),
+		rivers.WithParseMode(rivers.ParseLenient),
+	)
+	got, err := client.GetHistory(context.Background(), 1041, rivers.SensorLevel, rivers.PeriodDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Readings) != 2 {
+		t.Errorf("want 2 readings, got %d", len(got.Readings))
+	}
+	if len(got.Rejected) != 1 || got.Rejected[0].Line != 3 {
+		t.Errorf("want 1 rejected record on line 3, got %+v", got.Rejected)
+	}
+}
+
+func TestRiversClient_GetDayLevelSkipsRejectedRecordsInLenientMode(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		io.WriteString(rw, "datetime,value\n2021-07-10 00:00,0.294\n2021-07-10 00:15,abc\n2021-07-10 00:30,0.293\n")
+	}))
+	t.Cleanup(ts.Close)
+
+	client := newTestClient(t,
+		rivers.WithBaseURL(ts.URL),
+		rivers.WithParseMode(rivers.ParseLenient),
+	)
+	got, err := client.GetDayLevel(context.Background(), 1041)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Errorf("want 2 readings, got %d", len(got))
+	}
+}
+
+func TestRiversClient_GetGroupReadingsReportsRejectedValuesInLenientMode(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		io.WriteString(rw, "datetime,Brownsbarn,Mount Juliet\n2021-06-15 22:00,0.413,abc\n")
+	}))
+	t.Cleanup(ts.Close)
+
+	client := newTestClient(t,
+		rivers.WithBaseURL(ts.URL),
+		rivers.WithParseMode(rivers.ParseLenient),
+	)
+	got, err := client.GetGroupReadings(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Group.ID != 1 {
+		t.Errorf("want readings of group 1, got group %d", got.Group.ID)
+	}
+	if len(got.Readings) != 1 || got.Readings[0].Name != "Brownsbarn" {
+		t.Errorf("want 1 reading from Brownsbarn, got %+v", got.Readings)
+	}
+	if len(got.Rejected) != 1 || got.Rejected[0].Column != "Mount Juliet" {
+		t.Errorf("want 1 rejected value for Mount Juliet, got %+v", got.Rejected)
+	}
+}
+
+func TestRiversClient_GetHistoryErrorsOnInvalidPeriod(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(t)
+	_, err := client.GetHistory(context.Background(), 1041, rivers.SensorLevel, rivers.Period("year"))
+	if err == nil {
+		t.Fatal("want error on invalid period")
+	}
+}
+
+func TestRiversClient_GetHistoryErrorsOnUnknownSensor(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(t)
+	_, err := client.GetHistory(context.Background(), 1041, rivers.SensorUnknown, rivers.PeriodDay)
+	if err == nil {
+		t.Fatal("want error on unknown sensor")
+	}
+}
+
+func TestRiversClient_GetHistoryErrorsOnSensorWithoutHistoryFiles(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		t.Errorf("want no request, got request to %q", r.URL.Path)
+	}))
+	t.Cleanup(ts.Close)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+	_, err := client.GetHistory(context.Background(), 1041, rivers.SensorLevelOD, rivers.PeriodDay)
+	if err == nil {
+		t.Fatal("want error on level_od sensor")
+	}
+}
+
 func TestRiversClient_RetrievesGroupWaterLevel(t *testing.T) {
 	t.Parallel()
 	ts := newTestServer("/data/group", "testdata/group_1.csv", t)
 
-	client := rivers.NewClient()
-	client.BaseURL = ts.URL
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
 
-	want := []rivers.StationWaterLevelReading{
+	want := []rivers.StationGroupReading{
 		{
-			Name:       "John's Bridge Nore",
-			Readtime:   time.Date(2021, 06, 15, 22, 00, 00, 00, time.UTC),
-			WaterLevel: 466,
+			GroupID:      1,
+			GroupName:    "Nore",
+			StationID:    15002,
+			Name:         "John's Bridge Nore",
+			Readtime:     time.Date(2021, 06, 15, 22, 00, 00, 00, dublin),
+			ReadingValue: 466,
 		},
 		{
-			Name:       "Dinin Bridge",
-			Readtime:   time.Date(2021, 06, 15, 22, 00, 00, 00, time.UTC),
-			WaterLevel: 53,
+			GroupID:      1,
+			GroupName:    "Nore",
+			StationID:    15003,
+			Name:         "Dinin Bridge",
+			Readtime:     time.Date(2021, 06, 15, 22, 00, 00, 00, dublin),
+			ReadingValue: 53,
 		},
 		{
-			Name:       "Brownsbarn",
-			Readtime:   time.Date(2021, 06, 15, 22, 00, 00, 00, time.UTC),
-			WaterLevel: 413,
+			GroupID:      1,
+			GroupName:    "Nore",
+			StationID:    15006,
+			Name:         "Brownsbarn",
+			Readtime:     time.Date(2021, 06, 15, 22, 00, 00, 00, dublin),
+			ReadingValue: 413,
 		},
 		{
-			Name:       "Mount Juliet",
-			Readtime:   time.Date(2021, 06, 15, 22, 00, 00, 00, time.UTC),
-			WaterLevel: 451,
+			GroupID:      1,
+			GroupName:    "Nore",
+			StationID:    15011,
+			Name:         "Mount Juliet",
+			Readtime:     time.Date(2021, 06, 15, 22, 00, 00, 00, dublin),
+			ReadingValue: 451,
 		},
 	}
 
@@ -289,6 +679,145 @@ func TestRiversClient_RetrievesGroupWaterLevel(t *testing.T) {
 	}
 }
 
+func TestRiversClient_RetrievesGroupWaterLevelByName(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		wantPath := "/data/group/group_1.csv"
+		if r.URL.Path != wantPath {
+			t.Errorf("want request to %q, got %q", wantPath, r.URL.Path)
+		}
+		http.ServeFile(rw, r, "testdata/group_1.csv")
+	}))
+	t.Cleanup(ts.Close)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+
+	got, err := client.GetGroupWaterLevelByName(context.Background(), "nore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("want 4 readings, got %d", len(got))
+	}
+	if got[0].GroupID != 1 || got[0].GroupName != "Nore" {
+		t.Errorf("want reading for group 1 Nore, got %+v", got[0])
+	}
+}
+
+func TestRiversClient_GroupWaterLevelErrorsOnUnknownGroup(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(t, rivers.WithBaseURL("http://127.0.0.1:0"))
+
+	for _, id := range []int{0, 7, 29} {
+		_, err := client.GetGroupWaterLevel(context.Background(), id)
+		if !errors.Is(err, rivers.ErrGroupNotFound) {
+			t.Errorf("group %d: want ErrGroupNotFound, got %v", id, err)
+		}
+	}
+	_, err := client.GetGroupWaterLevelByName(context.Background(), "Liffey")
+	if !errors.Is(err, rivers.ErrGroupNotFound) {
+		t.Errorf("want ErrGroupNotFound, got %v", err)
+	}
+}
+
+func TestNewClient_AppliesOptions(t *testing.T) {
+	t.Parallel()
+	hc := &http.Client{Timeout: time.Second}
+	client, err := rivers.NewClient(
+		rivers.WithBaseURL("https://mirror.example.com/"),
+		rivers.WithHTTPClient(hc),
+		rivers.WithUserAgent("Mirror/1.0"),
+		rivers.WithRetryPolicy(rivers.NoRetryPolicy()),
+		rivers.WithConcurrency(8),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.BaseURL != "https://mirror.example.com" {
+		t.Errorf("want base URL without trailing slash, got %q", client.BaseURL)
+	}
+	if client.HTTPClient != hc {
+		t.Error("want custom HTTP client")
+	}
+	if client.UserAgent != "Mirror/1.0" {
+		t.Errorf("want user agent Mirror/1.0, got %q", client.UserAgent)
+	}
+	if client.Retry.MaxAttempts != 1 {
+		t.Errorf("want no retries, got %d attempts", client.Retry.MaxAttempts)
+	}
+	if client.Concurrency != 8 {
+		t.Errorf("want concurrency 8, got %d", client.Concurrency)
+	}
+}
+
+func TestNewClient_ErrorsOnInvalidOptions(t *testing.T) {
+	t.Parallel()
+	tests := map[string]rivers.ClientOption{
+		"base URL without scheme": rivers.WithBaseURL("waterlevel.ie"),
+		"base URL without host":   rivers.WithBaseURL("https://"),
+		"nil HTTP client":         rivers.WithHTTPClient(nil),
+		"nil transport":           rivers.WithTransport(nil),
+		"empty user agent":        rivers.WithUserAgent(""),
+		"zero concurrency":        rivers.WithConcurrency(0),
+	}
+	for name, opt := range tests {
+		if _, err := rivers.NewClient(opt); err == nil {
+			t.Errorf("%s: want error", name)
+		}
+	}
+}
+
+type userAgentRecorder struct {
+	userAgent string
+}
+
+func (r *userAgentRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.userAgent = req.Header.Get("User-Agent")
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewClient_SendsRequestsThroughCustomTransport(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer("/data/day", "testdata/day_01041_0001.csv", t)
+	rt := &userAgentRecorder{}
+
+	client := newTestClient(t,
+		rivers.WithBaseURL(ts.URL),
+		rivers.WithTransport(rt),
+		rivers.WithUserAgent("Instrumented/1.0"),
+	)
+	if _, err := client.GetDayLevel(context.Background(), 1041); err != nil {
+		t.Fatal(err)
+	}
+	if rt.userAgent != "Instrumented/1.0" {
+		t.Errorf("want request sent through custom transport, got user agent %q", rt.userAgent)
+	}
+}
+
+func newTestClient(t *testing.T, opts ...rivers.ClientOption) *rivers.Client {
+	t.Helper()
+	client, err := rivers.NewClient(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// newStationsTestServer returns the server that serves the stations
+// feed and the latest readings feed from the given files.
+func newStationsTestServer(stationsFile, latestFile string, t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/geojson/", func(rw http.ResponseWriter, r *http.Request) {
+		http.ServeFile(rw, r, stationsFile)
+	})
+	mux.HandleFunc("/geojson/latest", func(rw http.ResponseWriter, r *http.Request) {
+		http.ServeFile(rw, r, latestFile)
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
 func newTestServer(path string, datafile string, t *testing.T) *httptest.Server {
 	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
 		f, err := os.Open(datafile)
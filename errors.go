@@ -0,0 +1,131 @@
+package rivers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodyExcerpt limits the number of bytes of the
+// response body kept in the APIError.
+const maxErrorBodyExcerpt = 512
+
+var (
+	// ErrStationNotFound is the error used for indicating that
+	// the service has no data for the requested station and sensor.
+	ErrStationNotFound = errors.New("station not found")
+
+	// ErrRateLimited is the error used for indicating that the
+	// service rejected the request due to too many requests.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrServiceUnavailable is the error used for indicating
+	// that the service failed to handle the request.
+	ErrServiceUnavailable = errors.New("service unavailable")
+)
+
+// APIError represents an unsuccessful response received
+// from the water level service.
+//
+// Use errors.As to inspect the status code and the request context,
+// or errors.Is with ErrStationNotFound, ErrGroupNotFound, ErrRateLimited
+// and ErrServiceUnavailable to decide how to handle the failure.
+type APIError struct {
+	StatusCode int
+	URL        string
+
+	// StationID, Sensor and GroupID describe what data was
+	// requested. They are set only for requests they apply to.
+	StationID StationRef
+	Sensor    SensorKind
+	GroupID   int
+
+	// Code and Message hold the error details decoded from
+	// the response body, if the service sent them.
+	Code    int
+	Message string
+
+	// Body holds the beginning of the response body.
+	Body string
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "request to %s failed with status %d", e.URL, e.StatusCode)
+	if !e.StationID.IsZero() {
+		fmt.Fprintf(&b, ", station %s", e.StationID)
+	}
+	if e.Sensor != SensorUnknown {
+		fmt.Fprintf(&b, ", sensor %s", e.Sensor)
+	}
+	if e.GroupID != 0 {
+		fmt.Fprintf(&b, ", group %d", e.GroupID)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	return b.String()
+}
+
+// Is reports whether the error matches one of the sentinel errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrStationNotFound:
+		return e.StatusCode == http.StatusNotFound && !e.StationID.IsZero()
+	case ErrGroupNotFound:
+		return e.StatusCode == http.StatusNotFound && e.GroupID != 0
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServiceUnavailable:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// newAPIError takes unsuccessful HTTP response and builds the APIError.
+// It decodes error details from the response body if the body
+// holds a JSON error message.
+func newAPIError(res *http.Response) *APIError {
+	apiErr := APIError{
+		StatusCode: res.StatusCode,
+	}
+	if res.Request != nil && res.Request.URL != nil {
+		apiErr.URL = res.Request.URL.String()
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxErrorBodyExcerpt))
+	if err != nil {
+		return &apiErr
+	}
+	apiErr.Body = string(body)
+
+	var errRes errResponse
+	if err := json.Unmarshal(body, &errRes); err == nil {
+		apiErr.Code = errRes.Code
+		apiErr.Message = errRes.Message
+	}
+	return &apiErr
+}
+
+// withStation is a helper func that annotates the APIError,
+// if present in the chain, with the requested station and sensor.
+func withStation(err error, stationID StationRef, sensor SensorKind) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		apiErr.StationID = stationID
+		apiErr.Sensor = sensor
+	}
+	return err
+}
+
+// withGroup is a helper func that annotates the APIError,
+// if present in the chain, with the requested group.
+func withGroup(err error, groupID int) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		apiErr.GroupID = groupID
+	}
+	return err
+}
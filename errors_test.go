@@ -0,0 +1,90 @@
+package rivers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qba73/rivers"
+)
+
+func newErrorServer(status int, body string, t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(status)
+		rw.Write([]byte(body))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestClient_ReturnsAPIErrorWithStationContextOnNotFound(t *testing.T) {
+	t.Parallel()
+	ts := newErrorServer(http.StatusNotFound, `{"code": 404, "message": "no such file"}`, t)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+
+	_, err := client.GetDayTemperature(context.Background(), 1041)
+	if !errors.Is(err, rivers.ErrStationNotFound) {
+		t.Fatalf("want ErrStationNotFound, got %v", err)
+	}
+	var apiErr *rivers.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("want *APIError, got %T", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("want status code 404, got %d", apiErr.StatusCode)
+	}
+	if apiErr.StationID != 1041 || apiErr.Sensor != rivers.SensorTemperature {
+		t.Errorf("want station 01041 and temperature sensor, got %s and %s", apiErr.StationID, apiErr.Sensor)
+	}
+	if apiErr.URL != ts.URL+"/data/day/01041_0002.csv" {
+		t.Errorf("unexpected URL %q", apiErr.URL)
+	}
+	if apiErr.Code != 404 || apiErr.Message != "no such file" {
+		t.Errorf("want decoded error response, got code %d message %q", apiErr.Code, apiErr.Message)
+	}
+}
+
+func TestClient_ReturnsAPIErrorWithBodyExcerptOnNonJSONResponse(t *testing.T) {
+	t.Parallel()
+	ts := newErrorServer(http.StatusForbidden, "<html>forbidden</html>", t)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+
+	_, err := client.GetLatestReadings(context.Background())
+	var apiErr *rivers.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("want *APIError, got %v", err)
+	}
+	if apiErr.Body != "<html>forbidden</html>" {
+		t.Errorf("want body excerpt, got %q", apiErr.Body)
+	}
+	if apiErr.Message != "" {
+		t.Errorf("want empty message, got %q", apiErr.Message)
+	}
+	if errors.Is(err, rivers.ErrStationNotFound) {
+		t.Error("want error not to match ErrStationNotFound")
+	}
+}
+
+func TestClient_ReturnsServiceUnavailableOnServerError(t *testing.T) {
+	t.Parallel()
+	ts := newErrorServer(http.StatusServiceUnavailable, "", t)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+	client.Retry = rivers.NoRetryPolicy()
+
+	_, err := client.GetGroupWaterLevel(context.Background(), 1)
+	if !errors.Is(err, rivers.ErrServiceUnavailable) {
+		t.Fatalf("want ErrServiceUnavailable, got %v", err)
+	}
+	var apiErr *rivers.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("want *APIError, got %T", err)
+	}
+	if apiErr.GroupID != 1 {
+		t.Errorf("want group 1, got %d", apiErr.GroupID)
+	}
+}
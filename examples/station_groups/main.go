@@ -9,11 +9,14 @@ import (
 )
 
 func main() {
-	client := rivers.NewClient()
+	client, err := rivers.NewClient()
+	if err != nil {
+		log.Fatalln(err)
+	}
 
-	// groupID indicates which group station readings to retrieve.
-	// groupID value is between 1 and 28.
-	stations, err := client.GetGroupWaterLevel(context.Background(), 1)
+	// Group name indicates which group station readings to retrieve.
+	// rivers.Groups() returns names and IDs of all available groups.
+	stations, err := client.GetGroupWaterLevelByName(context.Background(), "Nore")
 	if err != nil {
 		log.Fatalln(err)
 	}
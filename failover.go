@@ -0,0 +1,62 @@
+package rivers
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// endpoints returns base URLs of the service in the order
+// they are tried: the BaseURL first, then FallbackURLs.
+func (c *Client) endpoints() []string {
+	return append([]string{c.BaseURL}, c.FallbackURLs...)
+}
+
+// sendRequestWithFailover sends the request to the first endpoint and
+// fails over to the next one on transport errors and 5xx responses.
+// It returns the response or the error received from the last tried
+// endpoint. The URL of the response request reports which endpoint
+// answered.
+func (c *Client) sendRequestWithFailover(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	endpoints := c.endpoints()
+	path, ok := strings.CutPrefix(req.URL.String(), c.BaseURL)
+	if !ok {
+		// The request does not target the service, so there
+		// is nothing to fail over to.
+		endpoints = endpoints[:1]
+	}
+
+	for i, endpoint := range endpoints {
+		r := req
+		if i > 0 {
+			u, err := url.Parse(endpoint + path)
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.URL = u
+			r.Host = ""
+		}
+		if err := c.RateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		res, err := c.HTTPClient.Do(r)
+		if err == nil && res.StatusCode < http.StatusInternalServerError {
+			return res, nil
+		}
+		if i == len(endpoints)-1 || ctx.Err() != nil {
+			return res, err
+		}
+		if err != nil {
+			c.logf("rivers: request to %s failed: %v, failing over to %s", endpoint, err, endpoints[i+1])
+			continue
+		}
+		c.logf("rivers: request to %s failed with status %d, failing over to %s", endpoint, res.StatusCode, endpoints[i+1])
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+	}
+	// Not reached, the loop returns on the last endpoint.
+	return nil, nil
+}
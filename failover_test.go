@@ -0,0 +1,117 @@
+package rivers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/qba73/rivers"
+)
+
+func TestNewClient_UsesHTTPSByDefault(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(t)
+	if !strings.HasPrefix(client.BaseURL, "https://") {
+		t.Errorf("want https base URL, got %q", client.BaseURL)
+	}
+}
+
+func TestClient_FailsOverToFallbackOnServerError(t *testing.T) {
+	t.Parallel()
+	var mirrorCalls int32
+	mirror := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&mirrorCalls, 1)
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(mirror.Close)
+	upstream := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/data/day/01041_0001.csv" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		http.ServeFile(rw, r, "testdata/day_01041_0001.csv")
+	}))
+	t.Cleanup(upstream.Close)
+
+	client := newTestClient(t,
+		rivers.WithBaseURL(mirror.URL),
+		rivers.WithFallbackURLs(upstream.URL),
+		rivers.WithRetryPolicy(rivers.NoRetryPolicy()),
+	)
+	got, err := client.GetDayLevel(context.Background(), 1041)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Errorf("want 4 readings, got %d", len(got))
+	}
+	if mirrorCalls != 1 {
+		t.Errorf("want 1 request to the mirror, got %d", mirrorCalls)
+	}
+}
+
+func TestClient_FailsOverToFallbackOnTransportError(t *testing.T) {
+	t.Parallel()
+	mirror := httptest.NewServer(http.NotFoundHandler())
+	mirrorURL := mirror.URL
+	mirror.Close()
+	upstream := newTestServer("/geojson/latest", "testdata/latest_short.json", t)
+
+	client := newTestClient(t,
+		rivers.WithBaseURL(mirrorURL),
+		rivers.WithFallbackURLs(upstream.URL),
+		rivers.WithRetryPolicy(rivers.NoRetryPolicy()),
+	)
+	got, err := client.GetLatestWaterLevels(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Errorf("want 1 reading, got %d", len(got))
+	}
+}
+
+func TestClient_DoesNotFailOverOnClientError(t *testing.T) {
+	t.Parallel()
+	mirror := newErrorServer(http.StatusNotFound, "", t)
+	var upstreamCalls int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&upstreamCalls, 1)
+	}))
+	t.Cleanup(upstream.Close)
+
+	client := newTestClient(t,
+		rivers.WithBaseURL(mirror.URL),
+		rivers.WithFallbackURLs(upstream.URL),
+	)
+	_, err := client.GetDayLevel(context.Background(), 1041)
+	if !errors.Is(err, rivers.ErrStationNotFound) {
+		t.Fatalf("want ErrStationNotFound, got %v", err)
+	}
+	if upstreamCalls != 0 {
+		t.Errorf("want no requests to the fallback, got %d", upstreamCalls)
+	}
+}
+
+func TestClient_ReportsLastTriedEndpointInError(t *testing.T) {
+	t.Parallel()
+	mirror := newErrorServer(http.StatusServiceUnavailable, "", t)
+	upstream := newErrorServer(http.StatusInternalServerError, "", t)
+
+	client := newTestClient(t,
+		rivers.WithBaseURL(mirror.URL),
+		rivers.WithFallbackURLs(upstream.URL),
+		rivers.WithRetryPolicy(rivers.NoRetryPolicy()),
+	)
+	_, err := client.GetDayLevel(context.Background(), 1041)
+	var apiErr *rivers.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("want *APIError, got %v", err)
+	}
+	if !strings.HasPrefix(apiErr.URL, upstream.URL) {
+		t.Errorf("want error to report fallback endpoint %q, got %q", upstream.URL, apiErr.URL)
+	}
+}
@@ -0,0 +1,134 @@
+package rivers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	columnDatetime = "datetime"
+	columnValue    = "value"
+)
+
+// ErrInvalidHeader is the error used for indicating that the csv
+// file header does not match the expected format, for example
+// when the upstream adds, renames or removes columns.
+var ErrInvalidHeader = errors.New("invalid csv header")
+
+// gaugeColumns holds positions of columns in a csv file
+// published for a single gauge.
+type gaugeColumns struct {
+	datetime int
+	value    int
+	count    int
+}
+
+// gaugeRecord holds fields of a csv record published
+// for a single gauge, mapped by column names.
+type gaugeRecord struct {
+	datetime string
+	value    string
+}
+
+// parseGaugeHeader maps columns of a csv file published for a single
+// gauge by their names. Columns may come in any order. The file must
+// have `datetime` and `value` columns. It errors on missing, duplicated
+// and unknown columns, including quality flag columns, as none of the
+// files published by the service carries one.
+func parseGaugeHeader(header []string) (gaugeColumns, error) {
+	cols := gaugeColumns{datetime: -1, value: -1, count: len(header)}
+	for i, name := range header {
+		var pos *int
+		switch normalizeColumnName(name) {
+		case columnDatetime:
+			pos = &cols.datetime
+		case columnValue:
+			pos = &cols.value
+		default:
+			return gaugeColumns{}, fmt.Errorf("%w %v: unexpected column %q", ErrInvalidHeader, header, name)
+		}
+		if *pos != -1 {
+			return gaugeColumns{}, fmt.Errorf("%w %v: duplicated column %q", ErrInvalidHeader, header, name)
+		}
+		*pos = i
+	}
+	if cols.datetime == -1 {
+		return gaugeColumns{}, fmt.Errorf("%w %v: missing column %q", ErrInvalidHeader, header, columnDatetime)
+	}
+	if cols.value == -1 {
+		return gaugeColumns{}, fmt.Errorf("%w %v: missing column %q", ErrInvalidHeader, header, columnValue)
+	}
+	return cols, nil
+}
+
+// split maps fields of the record to the gauge record.
+// It errors if the record has a different number of fields than the header.
+func (c gaugeColumns) split(record []string) (gaugeRecord, error) {
+	if len(record) != c.count {
+		return gaugeRecord{}, fmt.Errorf("invalid record %v, expecting %d fields", record, c.count)
+	}
+	return gaugeRecord{
+		datetime: record[c.datetime],
+		value:    record[c.value],
+	}, nil
+}
+
+// groupColumns holds position of the datetime column and
+// positions and names of station columns in a csv file
+// published for a group of stations.
+type groupColumns struct {
+	datetime int
+	stations []groupStation
+	count    int
+}
+
+type groupStation struct {
+	column int
+	name   string
+	refID  StationRef
+}
+
+// parseGroupHeader maps columns of a csv file published for a group
+// of stations. The file must have the `Datetime` column and at least
+// one station column. Station names are resolved to station references
+// using the bundled station catalogue.
+func parseGroupHeader(header []string) (groupColumns, error) {
+	cat, err := loadCatalogue()
+	if err != nil {
+		return groupColumns{}, err
+	}
+	cols := groupColumns{datetime: -1, count: len(header)}
+	for i, name := range header {
+		if normalizeColumnName(name) == columnDatetime {
+			if cols.datetime != -1 {
+				return groupColumns{}, fmt.Errorf("%w %v: duplicated column %q", ErrInvalidHeader, header, name)
+			}
+			cols.datetime = i
+			continue
+		}
+		// Some headers in csv files come with empty spaces, so trim them.
+		station := groupStation{column: i, name: strings.TrimSpace(name)}
+		if station.name == "" {
+			return groupColumns{}, fmt.Errorf("%w %v: empty station name in column %d", ErrInvalidHeader, header, i+1)
+		}
+		if ref, ok := cat.stationRef(name); ok {
+			station.refID = ref
+		}
+		cols.stations = append(cols.stations, station)
+	}
+	if cols.datetime == -1 {
+		return groupColumns{}, fmt.Errorf("%w %v: missing column %q", ErrInvalidHeader, header, columnDatetime)
+	}
+	if len(cols.stations) == 0 {
+		return groupColumns{}, fmt.Errorf("%w %v: missing station", ErrInvalidHeader, header)
+	}
+	return cols, nil
+}
+
+// normalizeColumnName is a helper func that makes column names
+// comparable. It removes the byte order mark some editors add
+// at the beginning of csv files.
+func normalizeColumnName(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
@@ -0,0 +1,79 @@
+package rivers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Level represents water level expressed in millimeters.
+//
+// The water level service publishes levels in meters with three
+// decimal places, so millimeters keep the full precision of readings.
+// Values are rounded to the nearest millimeter, never truncated.
+type Level int
+
+// Unit represents a unit of length used for formatting water levels.
+type Unit int
+
+const (
+	UnitMeters Unit = iota
+	UnitMillimeters
+	UnitFeet
+)
+
+const millimetersPerFoot = 304.8
+
+// ParseLevel takes a string representing water level in meters,
+// as published by the water level service, and returns the Level.
+// It errors if the string does not represent a float value.
+func ParseLevel(s string) (Level, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing water level: %w", err)
+	}
+	return LevelFromMeters(v), nil
+}
+
+// LevelFromMeters converts water level in meters to the Level.
+func LevelFromMeters(m float64) Level {
+	return Level(math.Round(m * 1000))
+}
+
+// LevelFromFeet converts water level in feet to the Level.
+func LevelFromFeet(ft float64) Level {
+	return Level(math.Round(ft * millimetersPerFoot))
+}
+
+// Millimeters returns the water level in millimeters.
+func (l Level) Millimeters() int {
+	return int(l)
+}
+
+// Meters returns the water level in meters.
+func (l Level) Meters() float64 {
+	return float64(l) / 1000
+}
+
+// Feet returns the water level in feet.
+func (l Level) Feet() float64 {
+	return float64(l) / millimetersPerFoot
+}
+
+// Format returns the water level formatted in the given unit,
+// for example "1.772 m", "1772 mm" or "5.81 ft".
+func (l Level) Format(u Unit) string {
+	switch u {
+	case UnitMillimeters:
+		return strconv.Itoa(int(l)) + " mm"
+	case UnitFeet:
+		return strconv.FormatFloat(l.Feet(), 'f', 2, 64) + " ft"
+	default:
+		return strconv.FormatFloat(l.Meters(), 'f', 3, 64) + " m"
+	}
+}
+
+// String returns the water level formatted in meters.
+func (l Level) String() string {
+	return l.Format(UnitMeters)
+}
@@ -0,0 +1,78 @@
+package rivers_test
+
+import (
+	"testing"
+
+	"github.com/qba73/rivers"
+)
+
+func TestParseLevel_RoundsToNearestMillimeter(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		input string
+		want  rivers.Level
+	}{
+		{input: "0.293", want: 293},
+		{input: "0.29", want: 290},
+		{input: "1.005", want: 1005},
+		{input: "2.3", want: 2300},
+		{input: "0.0575", want: 58},
+		{input: "-0.113", want: -113},
+		{input: "0", want: 0},
+	}
+	for _, tc := range tt {
+		got, err := rivers.ParseLevel(tc.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.want != got {
+			t.Errorf("ParseLevel(%q): want %d, got %d", tc.input, tc.want, got)
+		}
+	}
+}
+
+func TestParseLevel_ErrorsOnInvalidInput(t *testing.T) {
+	t.Parallel()
+	_, err := rivers.ParseLevel("invalid")
+	if err == nil {
+		t.Error("want error on invalid input")
+	}
+}
+
+func TestLevel_ConvertsUnits(t *testing.T) {
+	t.Parallel()
+	l := rivers.Level(1772)
+	if got := l.Millimeters(); got != 1772 {
+		t.Errorf("want 1772 mm, got %d", got)
+	}
+	if got := l.Meters(); got != 1.772 {
+		t.Errorf("want 1.772 m, got %v", got)
+	}
+	if got := rivers.LevelFromFeet(l.Feet()); got != l {
+		t.Errorf("want %v after converting to feet and back, got %v", l, got)
+	}
+	if got := rivers.LevelFromFeet(1); got != 305 {
+		t.Errorf("want 1 ft to be 305 mm, got %d", got)
+	}
+}
+
+func TestLevel_FormatsInGivenUnit(t *testing.T) {
+	t.Parallel()
+	l := rivers.Level(1772)
+	tt := []struct {
+		unit rivers.Unit
+		want string
+	}{
+		{unit: rivers.UnitMeters, want: "1.772 m"},
+		{unit: rivers.UnitMillimeters, want: "1772 mm"},
+		{unit: rivers.UnitFeet, want: "5.81 ft"},
+	}
+	for _, tc := range tt {
+		if got := l.Format(tc.unit); tc.want != got {
+			t.Errorf("want %q, got %q", tc.want, got)
+		}
+	}
+	if got := l.String(); got != "1.772 m" {
+		t.Errorf("want %q, got %q", "1.772 m", got)
+	}
+}
@@ -0,0 +1,193 @@
+package rivers
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// ErrSchemaTooNew is the error used for indicating that the database
+// schema was upgraded by a newer version of the package, so this
+// version can't safely use it.
+var ErrSchemaTooNew = errors.New("database schema is newer than supported")
+
+// migration holds a single versioned schema change.
+type migration struct {
+	version int
+	name    string
+	stmt    string
+}
+
+// loadMigrations reads embedded migrations and orders them by version.
+// Migration files are named <version>_<description>.sql, for example
+// 0001_create_waterlevel_readings.sql. Versions start from 1 and
+// must not have gaps, so each version is applied exactly once.
+func loadMigrations(fsys fs.FS) ([]migration, error) {
+	names, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("loading migrations: %w", err)
+	}
+	migrations := make([]migration, 0, len(names))
+	for _, name := range names {
+		base := path.Base(name)
+		prefix, _, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("loading migrations: invalid file name %q", base)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("loading migrations: invalid version in file name %q", base)
+		}
+		stmt, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("loading migrations: %w", err)
+		}
+		migrations = append(migrations, migration{version: version, name: base, stmt: string(stmt)})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	for i, m := range migrations {
+		if m.version != i+1 {
+			return nil, fmt.Errorf("loading migrations: missing or duplicated version %d, got %q", i+1, m.name)
+		}
+	}
+	return migrations, nil
+}
+
+// Migrate creates the database schema, or upgrades it to the latest
+// version. Each migration is applied in its own transaction and
+// recorded in the schema_version table, so Migrate is safe to call
+// on every start. NewSQLiteStore calls it when it opens the database.
+//
+// It errors with ErrSchemaTooNew if the database was migrated by
+// a newer version of the package.
+func (s *SQLiteStore) Migrate(ctx context.Context) error {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		return err
+	}
+	const createVersionTable = `CREATE TABLE IF NOT EXISTS schema_version (
+    version INTEGER PRIMARY KEY,
+    applied_at TEXT NOT NULL
+)`
+	if _, err := s.DB.ExecContext(ctx, createVersionTable); err != nil {
+		return fmt.Errorf("creating schema_version table: %w", err)
+	}
+	current, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("%w: database version %d, supported version %d", ErrSchemaTooNew, current, len(migrations))
+	}
+	for _, m := range migrations[current:] {
+		if err := s.applyMigration(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMigration runs statements of the migration in a transaction
+// and records its version.
+//
+// Databases created from schemas that predate migrations may already
+// have columns added by later migrations. Statements adding a column
+// that already exists are skipped, so such databases can be upgraded.
+func (s *SQLiteStore) applyMigration(ctx context.Context, m migration) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("applying migration %s: %w", m.name, err)
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range splitStatements(m.stmt) {
+		if table, column, ok := addedColumn(stmt); ok {
+			exists, err := columnExists(ctx, tx, table, column)
+			if err != nil {
+				return fmt.Errorf("applying migration %s: %w", m.name, err)
+			}
+			if exists {
+				continue
+			}
+		}
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("applying migration %s: %w", m.name, err)
+		}
+	}
+	const recordVersion = `INSERT INTO schema_version (version, applied_at) VALUES (?, ?)`
+	if _, err := tx.ExecContext(ctx, recordVersion, m.version, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("recording migration %s: %w", m.name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("applying migration %s: %w", m.name, err)
+	}
+	return nil
+}
+
+// splitStatements splits the migration into single statements.
+// Comment lines are dropped. Migrations must not use semicolons
+// inside string literals.
+func splitStatements(migration string) []string {
+	var b strings.Builder
+	for _, line := range strings.Split(migration, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	var stmts []string
+	for _, stmt := range strings.Split(b.String(), ";") {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
+
+var addColumnStmt = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(\w+)\s+ADD\s+(?:COLUMN\s+)?(\w+)`)
+
+// addedColumn reports the table and the column
+// if the statement adds a column to a table.
+func addedColumn(stmt string) (table, column string, ok bool) {
+	m := addColumnStmt.FindStringSubmatch(stmt)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// columnExists reports whether the table has the given column.
+func columnExists(ctx context.Context, tx *sql.Tx, table, column string) (bool, error) {
+	const query = `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ? COLLATE NOCASE`
+	var n int
+	if err := tx.QueryRowContext(ctx, query, table, column).Scan(&n); err != nil {
+		return false, fmt.Errorf("checking column %s.%s: %w", table, column, err)
+	}
+	return n > 0, nil
+}
+
+// SchemaVersion returns the version of the database schema.
+// Zero means no migrations have been applied yet.
+func (s *SQLiteStore) SchemaVersion(ctx context.Context) (int, error) {
+	var version sql.NullInt64
+	err := s.DB.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_version`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("reading schema version: %w", err)
+	}
+	return int(version.Int64), nil
+}
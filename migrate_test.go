@@ -0,0 +1,280 @@
+package rivers_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qba73/rivers"
+)
+
+// latestSchemaVersion is the number of embedded migrations.
+const latestSchemaVersion = 5
+
+func TestNewSQLiteStore_CreatesSchemaInNewDatabase(t *testing.T) {
+	t.Parallel()
+	store, err := rivers.NewSQLiteStore(filepath.Join(t.TempDir(), "waterlevels.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.DB.Close() })
+
+	version, err := store.SchemaVersion(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != latestSchemaVersion {
+		t.Errorf("want schema version %d, got %d", latestSchemaVersion, version)
+	}
+	reading := rivers.StationWaterLevelReading{
+		StationID:  1041,
+		Name:       "Sandy Mills",
+		Readtime:   time.Date(2021, 2, 18, 6, 0, 0, 0, time.UTC),
+		WaterLevel: 1715,
+	}
+	if err := store.Save(reading); err != nil {
+		t.Fatalf("want to save reading in a new database, got %v", err)
+	}
+}
+
+func TestNewSQLiteStore_ReopensMigratedDatabase(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "waterlevels.db")
+	store, err := rivers.NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reading := rivers.StationWaterLevelReading{
+		StationID:  1041,
+		Name:       "Sandy Mills",
+		Readtime:   time.Date(2021, 2, 18, 6, 0, 0, 0, time.UTC),
+		WaterLevel: 1715,
+	}
+	if err := store.Save(reading); err != nil {
+		t.Fatal(err)
+	}
+	store.DB.Close()
+
+	store, err = rivers.NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.DB.Close() })
+	got, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Errorf("want 1 reading after reopening the database, got %d", len(got))
+	}
+	version, err := store.SchemaVersion(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != latestSchemaVersion {
+		t.Errorf("want schema version %d, got %d", latestSchemaVersion, version)
+	}
+}
+
+func TestNewSQLiteStore_UpgradesDatabaseCreatedWithoutMigrations(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "waterlevels.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Databases created before migrations were added have this schema.
+	const legacySchema = `CREATE TABLE waterlevel_readings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    station_id INT NOT NULL,
+    station_name CHAR(50) NOT NULL,
+    datetime TEXT NOT NULL,
+    value INTEGER
+);
+INSERT INTO waterlevel_readings (station_id, station_name, datetime, value) VALUES (1043, 'Ballybofey', datetime('2022-06-30 04:15:00-00:00'), 879);`
+	if _, err := db.Exec(legacySchema); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	store, err := rivers.NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.DB.Close() })
+	got, err := store.GetLastReadingForStationID(1043)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.WaterLevel != 879 || got.ErrCode != 0 {
+		t.Errorf("want existing reading kept without err_code, got %+v", got)
+	}
+}
+
+func TestNewSQLiteStore_UpgradesDatabaseCreatedWithQualityColumn(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "waterlevels.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Databases created from misc/sql/readings.sql after reading
+	// quality was added, but before migrations, have this schema.
+	const legacySchema = `CREATE TABLE waterlevel_readings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    station_id INT NOT NULL,
+    station_name CHAR(50) NOT NULL,
+    datetime TEXT NOT NULL,
+    value INTEGER,
+    quality TEXT NOT NULL DEFAULT 'unknown'
+);
+INSERT INTO waterlevel_readings (station_id, station_name, datetime, value, quality) VALUES (1043, 'Ballybofey', datetime('2022-06-30 04:15:00-00:00'), 879, 'ok');`
+	if _, err := db.Exec(legacySchema); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	store, err := rivers.NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.DB.Close() })
+	got, err := store.GetLastReadingForStationID(1043)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.WaterLevel != 879 {
+		t.Errorf("want existing reading kept, got %+v", got)
+	}
+	version, err := store.SchemaVersion(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != latestSchemaVersion {
+		t.Errorf("want schema version %d, got %d", latestSchemaVersion, version)
+	}
+}
+
+func TestNewSQLiteStore_UpgradesDatabaseWithSensorRefColumn(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "waterlevels.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The readings table from misc/sql/schema.sql.
+	const legacySchema = `CREATE TABLE waterlevel_readings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    station_id INT NOT NULL,
+    station_name CHAR(50) NOT NULL,
+    sensor_ref CHAR(20) NOT NULL,
+    datetime TEXT NOT NULL,
+    value REAL
+);`
+	if _, err := db.Exec(legacySchema); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	store, err := rivers.NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.DB.Close() })
+	reading := rivers.StationWaterLevelReading{
+		StationID:  1043,
+		Name:       "Ballybofey",
+		Readtime:   time.Date(2022, 6, 30, 4, 15, 0, 0, time.UTC),
+		WaterLevel: 8060,
+		Sensor:     rivers.SensorLevelOD,
+	}
+	if err := store.Save(reading); err != nil {
+		t.Fatalf("want to save reading in the upgraded database, got %v", err)
+	}
+	got, err := store.GetLastReadingForStationID(1043)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Sensor != rivers.SensorLevelOD {
+		t.Errorf("want reading from the level_od sensor, got %s", got.Sensor)
+	}
+}
+
+func TestNewSQLiteStore_ConvertsReadingsStoredWithSchemaSQL(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "waterlevels.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The readings table and rows from misc/sql/schema.sql, and the
+	// reading of station 1043 stored again with a normalised datetime.
+	const legacySchema = `CREATE TABLE waterlevel_readings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    station_id INT NOT NULL,
+    station_name CHAR(50) NOT NULL,
+    sensor_ref CHAR(20) NOT NULL,
+    datetime TEXT NOT NULL,
+    value REAL
+);
+INSERT INTO waterlevel_readings VALUES(1,1042,'Sandy Millss','0001','2022-06-28T04:45:00Z',0.38399999999999998578);
+INSERT INTO waterlevel_readings VALUES(2,1043,'Ballybofey','0001','2022-06-28T04:14:00Z',1.6790000000000000923);
+INSERT INTO waterlevel_readings VALUES(3,3055,'Glaslough','0001','2022-06-28T04:45:00Z',0.47799999999999993605);
+INSERT INTO waterlevel_readings VALUES(4,1043,'Ballybofey','0001','2022-06-28 04:14:00',1.6790000000000000923);`
+	if _, err := db.Exec(legacySchema); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	store, err := rivers.NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.DB.Close() })
+	readings, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readings) != 3 {
+		t.Fatalf("want 3 readings after removing the duplicate, got %d: %+v", len(readings), readings)
+	}
+	got, err := store.GetLastReadingForStationID(1043)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantReadtime := time.Date(2022, 6, 28, 4, 14, 0, 0, time.UTC)
+	if got.WaterLevel != 1679 || !got.Readtime.Equal(wantReadtime) {
+		t.Errorf("want reading of 1679mm at %s, got %dmm at %s", wantReadtime, got.WaterLevel, got.Readtime)
+	}
+	err = store.Save(rivers.StationWaterLevelReading{
+		StationID:  1043,
+		Name:       "Ballybofey",
+		Readtime:   wantReadtime,
+		WaterLevel: 1679,
+	})
+	if !errors.Is(err, rivers.ErrReadingExists) {
+		t.Errorf("want ErrReadingExists saving converted reading again, got %v", err)
+	}
+}
+
+func TestNewSQLiteStore_ErrorsOnSchemaFromNewerVersion(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "waterlevels.db")
+	store, err := rivers.NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = store.DB.Exec(`INSERT INTO schema_version (version, applied_at) VALUES (999, '2030-01-01T00:00:00Z')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.DB.Close()
+
+	_, err = rivers.NewSQLiteStore(path)
+	if !errors.Is(err, rivers.ErrSchemaTooNew) {
+		t.Errorf("want ErrSchemaTooNew, got %v", err)
+	}
+}
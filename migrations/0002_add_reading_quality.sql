@@ -0,0 +1 @@
+ALTER TABLE waterlevel_readings ADD COLUMN quality TEXT NOT NULL DEFAULT 'unknown';
@@ -0,0 +1,22 @@
+-- The column is named as in misc/sql/schema.sql. Databases created from
+-- that schema already have it, so adding the column is skipped for them.
+ALTER TABLE waterlevel_readings ADD COLUMN sensor_ref TEXT NOT NULL DEFAULT '0001';
+
+-- Databases created from misc/sql/schema.sql keep values in metres in
+-- a REAL column and read times in the ISO 8601 format. Convert values
+-- to millimetres and read times to the format of the datetime function,
+-- so they are read like other readings and deduplicated below.
+UPDATE waterlevel_readings SET value = CAST(ROUND(value * 1000) AS INTEGER)
+WHERE typeof(value) = 'real';
+
+UPDATE waterlevel_readings SET datetime = datetime(datetime)
+WHERE datetime <> datetime(datetime);
+
+-- Keep the first of duplicated readings, so the unique index can be created.
+DELETE FROM waterlevel_readings
+WHERE id NOT IN (
+    SELECT MIN(id) FROM waterlevel_readings GROUP BY station_id, sensor_ref, datetime
+);
+
+CREATE UNIQUE INDEX waterlevel_readings_station_sensor_datetime
+ON waterlevel_readings (station_id, sensor_ref, datetime);
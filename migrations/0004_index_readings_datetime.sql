@@ -0,0 +1,2 @@
+-- Serves time range queries that are not limited to given stations.
+CREATE INDEX waterlevel_readings_datetime ON waterlevel_readings (datetime);
@@ -0,0 +1,3 @@
+-- The err_code published with the reading replaces the quality column,
+-- which is no longer written.
+ALTER TABLE waterlevel_readings ADD COLUMN err_code INTEGER NOT NULL DEFAULT 0;
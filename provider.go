@@ -0,0 +1,62 @@
+package rivers
+
+import "context"
+
+// WaterLevelProvider is the interface that wraps
+// the GetLatestWaterLevels method.
+//
+// GetLatestWaterLevels returns latest water level readings
+// from all stations.
+type WaterLevelProvider interface {
+	GetLatestWaterLevels(ctx context.Context) ([]StationWaterLevelReading, error)
+}
+
+// ReadingsProvider is the interface that wraps
+// the GetLatestReadings method.
+//
+// GetLatestReadings returns latest readings from all sensors
+// installed in stations, regardless of the sensor kind.
+type ReadingsProvider interface {
+	GetLatestReadings(ctx context.Context) ([]SensorReading, error)
+}
+
+// HistoryProvider is the interface that wraps the GetHistory method.
+//
+// GetHistory returns readings recorded by the sensor of the given
+// kind installed in the station over the given period.
+type HistoryProvider interface {
+	GetHistory(ctx context.Context, stationID StationRef, sensor SensorKind, period Period) (TimeSeries, error)
+}
+
+// StationProvider is the interface that wraps the GetStations method.
+//
+// GetStations returns the catalogue of stations.
+type StationProvider interface {
+	GetStations(ctx context.Context) ([]Station, error)
+}
+
+// GroupProvider is the interface that wraps GetGroupWaterLevel
+// and GetGroupWaterLevelByName methods.
+//
+// Both methods return latest water level readings from stations
+// that belong to the group, identified by its id or its name.
+type GroupProvider interface {
+	GetGroupWaterLevel(ctx context.Context, groupID int) ([]StationGroupReading, error)
+	GetGroupWaterLevelByName(ctx context.Context, name string) ([]StationGroupReading, error)
+}
+
+// Provider is the interface that groups all methods
+// providing data recorded by the water level service.
+//
+// Client implements Provider. Consumers should depend on
+// the narrowest interface they need, so they can be tested
+// with a fake provider, like the one in the riverstest package.
+type Provider interface {
+	WaterLevelProvider
+	ReadingsProvider
+	HistoryProvider
+	StationProvider
+	GroupProvider
+}
+
+var _ Provider = (*Client)(nil)
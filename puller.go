@@ -2,7 +2,6 @@ package rivers
 
 import (
 	"context"
-	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -32,14 +31,22 @@ func WithLogger(l *log.Logger) option {
 }
 
 type Puller struct {
-	Client      *Client
+	Client      WaterLevelProvider
 	ReadingRepo *ReadingsRepo
 	Interval    time.Duration
 	Log         *log.Logger
 }
 
 func NewPuller(opts ...option) (*Puller, error) {
-	client := NewClient()
+	// Latest readings change at most every 15 minutes, so most polls
+	// are answered with "304 Not Modified" instead of the full feed.
+	client, err := NewClient(WithCache(&HTTPCache{
+		Store:        NewMemoryCache(DefaultMemoryCacheEntries),
+		StaleIfError: 15 * time.Minute,
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("%w: creating data puller", err)
+	}
 	store, err := NewSQLiteStore("waterlevels.db")
 	if err != nil {
 		return nil, fmt.Errorf("%w: creating data puller", err)
@@ -57,6 +64,9 @@ func NewPuller(opts ...option) (*Puller, error) {
 			return nil, fmt.Errorf("creating data puller: %w", err)
 		}
 	}
+	if client.Log == nil {
+		client.Log = p.Log
+	}
 
 	return &p, nil
 }
@@ -69,13 +79,11 @@ func (p *Puller) RunPeriodically(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("retriving water level data: %w", err)
 		}
-		for _, reading := range stationReadings {
-			err = p.ReadingRepo.Add(reading)
-			if errors.Is(err, ErrReadingExists) {
-				continue
-			}
+		res, err := p.ReadingRepo.AddBatch(ctx, stationReadings)
+		if err != nil {
+			return fmt.Errorf("saving water level data: %w", err)
 		}
-		p.Log.Printf("puller : Saved latest water levels, resuming in %s", p.Interval.String())
+		p.Log.Printf("puller : Saved latest water levels (new: %d, duplicates: %d), resuming in %s", res.Inserted, res.Duplicates, p.Interval.String())
 	}
 	return nil
 }
@@ -0,0 +1,105 @@
+package rivers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultQueryLimit is the number of readings returned
+	// in a page when the filter does not set the limit.
+	DefaultQueryLimit = 1000
+
+	// MaxQueryLimit is the maximum number of readings
+	// returned in a page.
+	MaxQueryLimit = 10000
+)
+
+// ErrInvalidCursor is the error used for indicating that the page
+// cursor passed in the ReadingFilter is malformed.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// SortOrder controls the order of readings returned by a query.
+type SortOrder int
+
+const (
+	// OrderAscending returns the oldest readings first.
+	OrderAscending SortOrder = iota
+
+	// OrderDescending returns the latest readings first.
+	OrderDescending
+)
+
+// ReadingFilter selects readings returned by Store.Query.
+// Zero value selects all water level readings, oldest first.
+type ReadingFilter struct {
+	// StationIDs limits readings to the given stations.
+	// Empty slice means all stations.
+	StationIDs []StationRef
+
+	// Sensor limits readings to the given water level sensor,
+	// SensorLevel or SensorLevelOD. SensorUnknown means SensorLevel.
+	Sensor SensorKind
+
+	// From and To limit readings to the [From, To) time range.
+	// Zero values leave the range open.
+	From time.Time
+	To   time.Time
+
+	// Order controls the order of readings by read time.
+	Order SortOrder
+
+	// Limit is the maximum number of readings in a page.
+	// Values lower than 1 mean DefaultQueryLimit. Values greater
+	// than MaxQueryLimit are lowered to MaxQueryLimit.
+	Limit int
+
+	// Cursor is the NextCursor of the previous page.
+	// Empty cursor means the first page.
+	Cursor string
+}
+
+// ReadingPage holds a page of readings returned by Store.Query.
+type ReadingPage struct {
+	Readings []StationWaterLevelReading
+
+	// NextCursor is used in the ReadingFilter to fetch the next page.
+	// It is empty if there are no more readings.
+	NextCursor string
+}
+
+// pageCursor holds the position of the last reading in a page.
+// Readings are ordered by read time, and by row id for
+// readings taken at the same time.
+type pageCursor struct {
+	datetime string
+	id       int64
+}
+
+func (c pageCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.datetime + "|" + strconv.FormatInt(c.id, 10)))
+}
+
+// decodeCursor parses the cursor returned in the ReadingPage.
+func decodeCursor(s string) (pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageCursor{}, fmt.Errorf("%w %q", ErrInvalidCursor, s)
+	}
+	datetime, idStr, ok := strings.Cut(string(data), "|")
+	if !ok {
+		return pageCursor{}, fmt.Errorf("%w %q", ErrInvalidCursor, s)
+	}
+	if _, err := time.Parse(sqliteTimeFormat, datetime); err != nil {
+		return pageCursor{}, fmt.Errorf("%w %q", ErrInvalidCursor, s)
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return pageCursor{}, fmt.Errorf("%w %q", ErrInvalidCursor, s)
+	}
+	return pageCursor{datetime: datetime, id: id}, nil
+}
@@ -1,6 +1,7 @@
 package rivers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 )
@@ -23,23 +24,36 @@ func (r *ReadingsRepo) List() ([]StationWaterLevelReading, error) {
 }
 
 // GetLastReadingForStationID retrieves latest water level reading for given station id.
-func (r *ReadingsRepo) GetLastReadingForStationID(stationID int) (StationWaterLevelReading, error) {
+func (r *ReadingsRepo) GetLastReadingForStationID(stationID StationRef) (StationWaterLevelReading, error) {
 	return r.Store.GetLastReadingForStationID(stationID)
 }
 
+// Query returns a page of readings selected by the filter.
+func (r *ReadingsRepo) Query(ctx context.Context, filter ReadingFilter) (ReadingPage, error) {
+	page, err := r.Store.Query(ctx, filter)
+	if err != nil {
+		return ReadingPage{}, fmt.Errorf("querying sensor readings: %w", err)
+	}
+	return page, nil
+}
+
 // Add takes a reading and adds it to the store.
+// It errors with ErrReadingExists if the reading is already stored.
 func (r *ReadingsRepo) Add(reading StationWaterLevelReading) error {
-	current, err := r.Store.GetLastReadingForStationID(reading.StationID)
-	if err != nil && !errors.Is(err, ErrNoReading) {
+	if err := r.Store.Save(reading); err != nil {
 		return fmt.Errorf("adding sensor reading: %w", err)
 	}
-	if errors.Is(err, ErrNoReading) {
-		return r.Store.Save(reading)
-	}
-	if current.StationID == reading.StationID && reading.Readtime.Equal(current.Readtime) {
-		return fmt.Errorf("adding sensor reading %v: %w", reading, ErrReadingExists)
+	return nil
+}
+
+// AddBatch takes readings and adds them to the store at once.
+// Readings already stored are skipped and counted as duplicates.
+func (r *ReadingsRepo) AddBatch(ctx context.Context, readings []StationWaterLevelReading) (SaveResult, error) {
+	res, err := r.Store.SaveBatch(ctx, readings)
+	if err != nil {
+		return SaveResult{}, fmt.Errorf("adding sensor readings: %w", err)
 	}
-	return r.Store.Save(reading)
+	return res, nil
 }
 
 var (
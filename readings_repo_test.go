@@ -1,6 +1,7 @@
 package rivers_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -32,30 +33,35 @@ func TestListGetsAllWaterLevelReadingsFromDatabase(t *testing.T) {
 			Name:       "Sandy Millss",
 			Readtime:   time.Date(2022, 06, 28, 04, 45, 00, 00, time.UTC),
 			WaterLevel: 383,
+			Sensor:     rivers.SensorLevel,
 		},
 		{
 			StationID:  1043,
 			Name:       "Ballybofey",
 			Readtime:   time.Date(2022, 06, 28, 04, 15, 00, 00, time.UTC),
 			WaterLevel: 679,
+			Sensor:     rivers.SensorLevel,
 		},
 		{
 			StationID:  1043,
 			Name:       "Ballybofey",
 			Readtime:   time.Date(2022, 06, 29, 05, 15, 00, 00, time.UTC),
 			WaterLevel: 779,
+			Sensor:     rivers.SensorLevel,
 		},
 		{
 			StationID:  1043,
 			Name:       "Ballybofey",
 			Readtime:   time.Date(2022, 06, 30, 04, 15, 00, 00, time.UTC),
 			WaterLevel: 879,
+			Sensor:     rivers.SensorLevel,
 		},
 		{
 			StationID:  3055,
 			Name:       "Glaslough",
 			Readtime:   time.Date(2022, 06, 28, 04, 45, 00, 00, time.UTC),
 			WaterLevel: 478,
+			Sensor:     rivers.SensorLevel,
 		},
 	}
 	if !cmp.Equal(want, got) {
@@ -80,6 +86,7 @@ func TestRetrieveLastReadingForOneStation(t *testing.T) {
 		Name:       "Ballybofey",
 		Readtime:   time.Date(2022, 06, 30, 04, 15, 00, 00, time.UTC),
 		WaterLevel: 879,
+		Sensor:     rivers.SensorLevel,
 	}
 	if !cmp.Equal(want, got) {
 		t.Error(cmp.Diff(want, got))
@@ -99,6 +106,7 @@ func TestAddSingleReadingToTheStore(t *testing.T) {
 		Name:       "Glaslough",
 		Readtime:   time.Now(),
 		WaterLevel: 991,
+		Sensor:     rivers.SensorLevel,
 	}
 	err := readings.Add(want)
 	if err != nil {
@@ -124,6 +132,7 @@ func TestAdd_DoesNotAddDuplicateReadings(t *testing.T) {
 	want := rivers.StationWaterLevelReading{
 		StationID: 3055,
 		Readtime:  time.Date(2022, 06, 28, 04, 15, 00, 00, time.UTC),
+		Sensor:    rivers.SensorLevel,
 	}
 	err := readings.Add(want)
 	if err != nil {
@@ -158,8 +167,11 @@ func newTestDB(stmtPopulateData string, t *testing.T) *sql.DB {
 	if err = db.Ping(); err != nil {
 		t.Fatal(err)
 	}
-	_, err = db.Exec(waterLevelSchema)
-	if err != nil {
+	// Each connection to an in-memory database opens a new database,
+	// so we make sure all queries use the same connection.
+	db.SetMaxOpenConns(1)
+	store := rivers.SQLiteStore{DB: db}
+	if err := store.Migrate(context.Background()); err != nil {
 		t.Fatal(err)
 	}
 	if stmtPopulateData != "" {
@@ -171,7 +183,7 @@ func newTestDB(stmtPopulateData string, t *testing.T) *sql.DB {
 	// Call the func to clean database after each test.
 	// This way we don't need to pullute test logic with `defer`.
 	t.Cleanup(func() {
-		if _, err := db.Exec(`DROP TABLE waterlevel_readings`); err != nil {
+		if _, err := db.Exec(`DROP TABLE waterlevel_readings; DROP TABLE schema_version`); err != nil {
 			t.Fatalf("error cleaning up test database: %#v", err)
 		}
 	})
@@ -179,14 +191,6 @@ func newTestDB(stmtPopulateData string, t *testing.T) *sql.DB {
 }
 
 var (
-	waterLevelSchema = `DROP TABLE IF EXISTS waterlevel_readings;
-CREATE TABLE waterlevel_readings (
-id INTEGER PRIMARY KEY AUTOINCREMENT,
-station_id INT NOT NULL,
-station_name CHAR(50) NOT NULL,
-datetime TEXT NOT NULL,
-value INTEGER);`
-
 	// DB statements for populating data
 	stmtRetrieveLastReadingForOneStation = `INSERT INTO "waterlevel_readings" (station_id, station_name, datetime, value) VALUES (1042,'Sandy Millss',datetime('2022-06-28 04:45:00-00:00'),383);
 INSERT INTO "waterlevel_readings" (station_id, station_name, datetime, value) VALUES(1043,'Ballybofey',datetime('2022-06-28 04:15:00-00:00'),679);
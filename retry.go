@@ -0,0 +1,174 @@
+package rivers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// RetryPolicy controls how the client retries failed requests.
+//
+// A request is retried when it fails with a transport error or when
+// the service responds with one of the RetryableStatusCodes. Delays
+// between attempts grow exponentially from BaseDelay up to MaxDelay.
+// A delay requested by the service in the Retry-After header takes
+// precedence over the computed one.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the
+	// first one. Values lower than 1 mean the request is sent once.
+	MaxAttempts int
+
+	// MaxElapsedTime limits the total time spent on all attempts.
+	// Zero value means no limit.
+	MaxElapsedTime time.Duration
+
+	// BaseDelay is the delay before the first retry.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between two attempts.
+	MaxDelay time.Duration
+
+	// RetryableStatusCodes holds HTTP status codes that
+	// indicate a transient failure of the service.
+	RetryableStatusCodes []int
+}
+
+// DefaultRetryPolicy returns the retry policy used by clients
+// created with NewClient.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		MaxElapsedTime: 2 * time.Minute,
+		BaseDelay:      time.Second,
+		MaxDelay:       30 * time.Second,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// NoRetryPolicy returns the retry policy that sends every request once.
+func NoRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+func (p RetryPolicy) retryableStatus(code int) bool {
+	return slices.Contains(p.RetryableStatusCodes, code)
+}
+
+// backoff returns the delay before the given retry attempt.
+// The first retry has attempt value 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay <<= 1
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// Add jitter so that many clients do not retry in lockstep.
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// sendRequestWithBackoff sends the request and retries it according
+// to the client retry policy. It returns the last received response
+// if all attempts fail with a retryable status code, so the caller
+// can inspect the status code.
+func (c *Client) sendRequestWithBackoff(req *http.Request) (*http.Response, error) {
+	policy := c.Retry
+	ctx := req.Context()
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		res, err := c.sendRequestWithFailover(req)
+		if err == nil && !policy.retryableStatus(res.StatusCode) {
+			return res, nil
+		}
+		if err != nil && ctx.Err() != nil {
+			return nil, err
+		}
+		if attempt >= policy.MaxAttempts {
+			return res, giveUp(attempt, err)
+		}
+
+		delay := policy.backoff(attempt)
+		if res != nil {
+			if d, ok := retryAfter(res, time.Now()); ok {
+				delay = d
+			}
+		}
+		if policy.MaxElapsedTime > 0 && time.Since(start)+delay > policy.MaxElapsedTime {
+			return res, giveUp(attempt, err)
+		}
+		if res != nil {
+			c.logf("rivers: request to %s failed with status %d, retrying in %s (attempt %d/%d)", req.URL, res.StatusCode, delay, attempt, policy.MaxAttempts)
+			// Drain the body so the connection can be reused.
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		} else {
+			c.logf("rivers: request to %s failed: %v, retrying in %s (attempt %d/%d)", req.URL, err, delay, attempt, policy.MaxAttempts)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func giveUp(attempts int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("giving up after %d attempt(s): %w", attempts, err)
+}
+
+// retryAfter returns the delay requested by the service in the
+// Retry-After header. The header value is either a number of
+// seconds or an HTTP date.
+func retryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	v := res.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// sleep pauses for the given duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,149 @@
+package rivers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qba73/rivers"
+)
+
+func newFlakyServer(failures int32, status int, header http.Header, t *testing.T) (*httptest.Server, *int32) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if n <= failures {
+			for k, v := range header {
+				rw.Header()[k] = v
+			}
+			rw.WriteHeader(status)
+			return
+		}
+		http.ServeFile(rw, r, "testdata/day_01041_0001.csv")
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &calls
+}
+
+func fastRetryPolicy(attempts int) rivers.RetryPolicy {
+	p := rivers.DefaultRetryPolicy()
+	p.MaxAttempts = attempts
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 5 * time.Millisecond
+	return p
+}
+
+func TestClient_RetriesRetryableStatusCodes(t *testing.T) {
+	t.Parallel()
+	ts, calls := newFlakyServer(2, http.StatusServiceUnavailable, nil, t)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+	client.Retry = fastRetryPolicy(3)
+
+	got, err := client.GetDayLevel(context.Background(), 1041)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Errorf("want 4 readings, got %d", len(got))
+	}
+	if *calls != 3 {
+		t.Errorf("want 3 requests, got %d", *calls)
+	}
+}
+
+func TestClient_StopsRetryingAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+	ts, calls := newFlakyServer(10, http.StatusBadGateway, nil, t)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+	client.Retry = fastRetryPolicy(3)
+
+	_, err := client.GetDayLevel(context.Background(), 1041)
+	if err == nil {
+		t.Fatal("want error after exhausting retries")
+	}
+	if *calls != 3 {
+		t.Errorf("want 3 requests, got %d", *calls)
+	}
+}
+
+func TestClient_DoesNotRetryNonRetryableStatusCodes(t *testing.T) {
+	t.Parallel()
+	ts, calls := newFlakyServer(10, http.StatusNotFound, nil, t)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+	client.Retry = fastRetryPolicy(3)
+
+	_, err := client.GetDayLevel(context.Background(), 1041)
+	if err == nil {
+		t.Fatal("want error on 404 response")
+	}
+	if *calls != 1 {
+		t.Errorf("want 1 request, got %d", *calls)
+	}
+}
+
+func TestClient_GivesUpWhenRetryAfterExceedsMaxElapsedTime(t *testing.T) {
+	t.Parallel()
+	header := http.Header{"Retry-After": []string{"120"}}
+	ts, calls := newFlakyServer(10, http.StatusTooManyRequests, header, t)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+	client.Retry = fastRetryPolicy(5)
+	client.Retry.MaxElapsedTime = time.Second
+
+	start := time.Now()
+	_, err := client.GetDayLevel(context.Background(), 1041)
+	if err == nil {
+		t.Fatal("want error on 429 response")
+	}
+	if *calls != 1 {
+		t.Errorf("want 1 request, got %d", *calls)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("client waited for Retry-After exceeding max elapsed time")
+	}
+}
+
+func TestClient_StopsRetryingWhenContextIsCancelled(t *testing.T) {
+	t.Parallel()
+	ts, _ := newFlakyServer(10, http.StatusServiceUnavailable, nil, t)
+
+	client := newTestClient(t, rivers.WithBaseURL(ts.URL))
+	client.Retry = rivers.DefaultRetryPolicy()
+	client.Retry.BaseDelay = time.Hour
+	client.Retry.MaxDelay = time.Hour
+	client.Retry.MaxElapsedTime = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetDayLevel(ctx, 1041)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want context.DeadlineExceeded, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("client did not stop sleeping on context cancellation")
+	}
+}
+
+func TestClient_StopsRetryingTransportErrorsAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.NotFoundHandler())
+	url := ts.URL
+	ts.Close()
+
+	client := newTestClient(t, rivers.WithBaseURL(url))
+	client.Retry = fastRetryPolicy(3)
+
+	_, err := client.GetDayLevel(context.Background(), 1041)
+	if err == nil {
+		t.Fatal("want error on unreachable server")
+	}
+}
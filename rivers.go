@@ -1,13 +1,12 @@
 package rivers
 
 import (
-	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strconv"
-	"strings"
 	"time"
 )
 
@@ -20,20 +19,59 @@ const (
 // recorded by a gauge at the given time.
 type WaterLevelReading struct {
 	Name      string
-	RefID     string
+	RefID     StationRef
 	Timestamp time.Time
-	Value     int
+	Value     Level
 }
 
 // WaterTemperatureReading holds information about water temperature
 // recorded by gauge at the given time.
 type WaterTemperatureReading struct {
 	Name      string
-	RefID     string
+	RefID     StationRef
 	Timestamp time.Time
 	Value     float64
 }
 
+// VoltageReading holds information about the voltage of the
+// gauge power supply recorded at the given time.
+type VoltageReading struct {
+	Name      string
+	RefID     StationRef
+	Timestamp time.Time
+	Value     float64
+}
+
+// Reading holds a single value recorded by a sensor at the given time.
+// The value is expressed in the sensor unit.
+type Reading struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// TimeSeries holds readings recorded by a station sensor over a period.
+//
+// Rejected holds csv records skipped when the series
+// was parsed in lenient mode.
+type TimeSeries struct {
+	StationID StationRef
+	Sensor    SensorKind
+	Period    Period
+	Readings  []Reading
+	Rejected  []RejectedRow
+}
+
+// GroupReadings holds water level readings recorded by stations
+// that belong to a group.
+//
+// Rejected holds csv values skipped when the readings
+// were parsed in lenient mode.
+type GroupReadings struct {
+	Group    Group
+	Readings []StationGroupReading
+	Rejected []RejectedRow
+}
+
 // LoadWaterLevelCSV knows how to open and read given csv file.
 // Upon successful run it returns a slice of level structs.
 func LoadWaterLevelCSV(path string) ([]WaterLevelReading, error) {
@@ -48,167 +86,136 @@ func LoadWaterLevelCSV(path string) ([]WaterLevelReading, error) {
 // ReadWaterLevelCSV knows how to read a csv file containing
 // readings from a single gauge.
 //
-// The func expects file header in the format: timestamp,level
+// The func expects file header with `datetime` and `value` columns.
+// Columns are mapped by name.
+// Timestamps are parsed in the DefaultLocation.
+// Use NewWaterLevelScanner to process large files record by record.
 func ReadWaterLevelCSV(r io.Reader) ([]WaterLevelReading, error) {
-	csvreader := csv.NewReader(r)
-	// We are not interested in the CSV header. We read it
-	// before start looping through the lines (records).
-	if _, err := csvreader.Read(); err != nil {
-		return nil, errors.New("reading csv file")
-	}
-	records, err := csvreader.ReadAll()
-	if err != nil {
-		return nil, err
-	}
-
-	levels := make([]WaterLevelReading, 0, len(records))
-	for _, r := range records {
-		level, err := processWaterLevelRecord(r)
-		if err != nil {
-			return nil, fmt.Errorf("error processing csv record: %v", err)
-		}
-		levels = append(levels, level)
-	}
-	return levels, nil
+	return collect(NewWaterLevelScanner(r))
 }
 
 // ReadWaterTemperatureCSV reads a csv file containing data from a gauge.
-// Epected format: `timestamp,value` where the `value` represents temperature in Celsius.
+// Expected format: `datetime,value` where the `value` represents temperature in Celsius.
 func ReadWaterTemperatureCSV(r io.Reader) ([]WaterTemperatureReading, error) {
-	csvreader := csv.NewReader(r)
-	// We are not interested in the CSV header. We read it
-	// before start looping through the lines (records).
-	if _, err := csvreader.Read(); err != nil {
-		return nil, errors.New("reading csv file")
-	}
+	return collect(NewWaterTemperatureScanner(r))
+}
 
-	records, err := csvreader.ReadAll()
-	if err != nil {
-		return nil, err
-	}
+// ReadVoltageCSV reads a csv file containing data from a gauge.
+// Expected format: `datetime,value` where the `value` represents voltage in Volts.
+func ReadVoltageCSV(r io.Reader) ([]VoltageReading, error) {
+	return collect(NewVoltageScanner(r))
+}
 
-	levels := make([]WaterTemperatureReading, 0, len(records))
-	for _, r := range records {
-		level, err := processWaterTempRecord(r)
-		if err != nil {
-			return nil, fmt.Errorf("processing csv record: %v", err)
-		}
-		levels = append(levels, level)
-	}
-	return levels, nil
+// ReadSensorCSV reads a csv file containing data from any gauge sensor.
+// Expected format: `datetime,value` where the `value` is expressed in the sensor unit.
+func ReadSensorCSV(r io.Reader) ([]Reading, error) {
+	return collect(NewSensorScanner(r))
 }
 
-func processWaterLevelRecord(r []string) (WaterLevelReading, error) {
-	tm, err := processTimestamp(r)
-	if err != nil {
-		return WaterLevelReading{}, err
-	}
-	val, err := processWaterLevelValue(r)
+func processWaterLevelRecord(tm time.Time, r gaugeRecord) (WaterLevelReading, error) {
+	val, err := processWaterLevelValue(r.value)
 	if err != nil {
 		return WaterLevelReading{}, err
 	}
 	return WaterLevelReading{Timestamp: tm, Value: val}, nil
 }
 
-func processWaterTempRecord(r []string) (WaterTemperatureReading, error) {
-	tm, err := processTimestamp(r)
+func processWaterTempRecord(tm time.Time, r gaugeRecord) (WaterTemperatureReading, error) {
+	val, err := processFloatValue(r.value)
 	if err != nil {
-		return WaterTemperatureReading{}, err
+		return WaterTemperatureReading{}, fmt.Errorf("processing water temp value: %w", err)
 	}
-	val, err := processWaterTempValue(r)
+	return WaterTemperatureReading{Timestamp: tm, Value: val}, nil
+}
+
+func processVoltageRecord(tm time.Time, r gaugeRecord) (VoltageReading, error) {
+	val, err := processFloatValue(r.value)
 	if err != nil {
-		return WaterTemperatureReading{}, err
+		return VoltageReading{}, fmt.Errorf("processing voltage value: %w", err)
 	}
-	return WaterTemperatureReading{Timestamp: tm, Value: val}, nil
+	return VoltageReading{Timestamp: tm, Value: val}, nil
 }
 
-func processTimestamp(record []string) (time.Time, error) {
-	if len(record) < 1 {
-		return time.Time{}, fmt.Errorf("processing timestamp: invalid record %v", record)
+func processSensorRecord(tm time.Time, r gaugeRecord) (Reading, error) {
+	val, err := processFloatValue(r.value)
+	if err != nil {
+		return Reading{}, fmt.Errorf("processing sensor value: %w", err)
 	}
-	return time.Parse(gaugeTimeFormat, record[0])
+	return Reading{Timestamp: tm, Value: val}, nil
 }
 
-func processWaterLevelValue(record []string) (int, error) {
-	if len(record) != 2 {
-		return 0, fmt.Errorf("processing water level value: invalid record %v", record)
+func processWaterLevelValue(value string) (Level, error) {
+	if err := checkValuePresent(value); err != nil {
+		return 0, fmt.Errorf("processing water level value: %w", err)
 	}
-	return toMillimeters(record[1])
+	return ParseLevel(value)
 }
 
-func processWaterTempValue(record []string) (float64, error) {
-	if len(record) != 2 {
-		return 0, fmt.Errorf("processing water temp value: invalid record %v", record)
+func processFloatValue(value string) (float64, error) {
+	if err := checkValuePresent(value); err != nil {
+		return 0, err
 	}
-	return strconv.ParseFloat(record[1], 64)
+	return strconv.ParseFloat(value, 64)
 }
 
+// ReadGroupCSV reads a csv file containing readings from a group of stations.
+// Expected format: `Datetime,<station name>,<station name>...`
+// It errors if the file does not contain any records.
 func ReadGroupCSV(r io.Reader) ([]WaterLevelReading, error) {
-	csvreader := csv.NewReader(r)
-	records, err := csvreader.ReadAll()
-	if err != nil {
-		return nil, err
-	}
-	return parseStationGroup(records)
+	return readGroup(NewGroupScanner(r))
 }
 
-func parseStationGroup(records [][]string) ([]WaterLevelReading, error) {
-	if len(records) < 2 {
-		return nil, fmt.Errorf("parsing station groups: empty records %v", records)
-	}
-	if len(records[0]) < 2 {
-		return nil, errors.New("parsing station groups: missing station")
+func readGroup(s *Scanner[WaterLevelReading]) ([]WaterLevelReading, error) {
+	readings, err := collect(s)
+	if err != nil {
+		return nil, err
 	}
-	var gsr []WaterLevelReading
-	stationNames := records[0][1:]
-	for _, record := range records[1:] {
-		timestamp, err := time.Parse(gaugeTimeFormat, record[0])
-		if err != nil {
-			return nil, err
-		}
-		for i, reading := range record[1:] {
-			// If reading value from sensor is not yet present we
-			// do not attemp to process it.
-			if reading == "" {
-				continue
-			}
-			levelValue, err := toMillimeters(reading)
-			if err != nil {
-				return nil, fmt.Errorf("parsing station groups: %w", err)
-			}
-			gr := WaterLevelReading{
-				// Some headers in csv files come with empty spaces, so trim them.
-				Name:      strings.TrimSpace(stationNames[i]),
-				Timestamp: timestamp,
-				Value:     levelValue,
-			}
-			gsr = append(gsr, gr)
-		}
+	if s.records == 0 {
+		return nil, errors.New("parsing station groups: empty records")
 	}
-	return gsr, nil
-}
-
-// WaterLevelProvider is the interface that wraps the GetLatestWaterLevels method.
-//
-// GetLatestWaterLevels returns a slice of water level readings from sensors.
-type WaterLevelProvider interface {
-	GetLatestWaterLevels() []StationWaterLevelReading
+	return readings, nil
 }
 
 // StationWaterLevelReading represents data received
 // from a water level sensor.
+//
+// Sensor is the water level sensor that took the reading, either
+// SensorLevel or SensorLevelOD, which reports the level relative
+// to the Ordnance Datum. Zero value means SensorLevel.
+//
+// ErrCode is the err_code published with the reading in the GeoJSON
+// feed. The service does not document the meaning of the codes.
 type StationWaterLevelReading struct {
-	StationID  int       `json:"station_id,omitempty"`
-	Name       string    `json:"name,omitempty"`
-	Readtime   time.Time `json:"readtime"`
-	WaterLevel int       `json:"water_level"`
+	StationID  StationRef `json:"station_id,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	Readtime   time.Time  `json:"readtime"`
+	WaterLevel Level      `json:"water_level"`
+	Sensor     SensorKind `json:"sensor,omitempty"`
+	ErrCode    int        `json:"err_code,omitempty"`
 }
 
+// StationGroupReading represents water level reading
+// recorded by a station that belongs to a group of stations.
+// In JSON the StationID is a string padded the same way as in
+// the GeoJSON feed, or an empty string if the station is unknown.
 type StationGroupReading struct {
-	GroupID      int       `json:"group_id"`
-	GroupName    string    `json:"group_name"`
-	StationID    string    `json:"station_id"`
-	Name         string    `json:"name,omitempty"`
-	Readtime     time.Time `json:"readtime"`
-	ReadingValue int       `json:"reading_value"`
+	GroupID      int        `json:"group_id"`
+	GroupName    string     `json:"group_name"`
+	StationID    StationRef `json:"station_id"`
+	Name         string     `json:"name,omitempty"`
+	Readtime     time.Time  `json:"readtime"`
+	ReadingValue Level      `json:"reading_value"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// It encodes the StationID as a padded string.
+func (r StationGroupReading) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		GroupID      int       `json:"group_id"`
+		GroupName    string    `json:"group_name"`
+		StationID    string    `json:"station_id"`
+		Name         string    `json:"name,omitempty"`
+		Readtime     time.Time `json:"readtime"`
+		ReadingValue Level     `json:"reading_value"`
+	}{r.GroupID, r.GroupName, r.StationID.jsonString(), r.Name, r.Readtime, r.ReadingValue})
 }
@@ -1,6 +1,7 @@
 package rivers_test
 
 import (
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -9,6 +10,9 @@ import (
 	"github.com/qba73/rivers"
 )
 
+// dublin is the time zone of timestamps in csv test files.
+var dublin = rivers.DefaultLocation()
+
 func TestLoadCSV_LoadsExistingFile(t *testing.T) {
 	t.Parallel()
 
@@ -49,7 +53,7 @@ func TestReadCSV(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	wantValue := 1772
+	wantValue := rivers.Level(1772)
 
 	want := rivers.WaterLevelReading{
 		Timestamp: wantTimestamp,
@@ -85,27 +89,55 @@ func TestParseStationGroup_ErrorsOnNoStationInCSV(t *testing.T) {
 	}
 }
 
+func TestParseStationGroup_LeavesRefIDZeroForUnknownStation(t *testing.T) {
+	t.Parallel()
+	got, err := rivers.ReadGroupCSV(strings.NewReader(validGroupInputUnknownStation))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []rivers.WaterLevelReading{
+		{
+			Name:      "Unknown Bridge",
+			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, dublin),
+			Value:     466,
+		},
+		{
+			Name:      "Dinin Bridge",
+			RefID:     15003,
+			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, dublin),
+			Value:     53,
+		},
+	}
+	if !cmp.Equal(want, got) {
+		t.Fatal(cmp.Diff(want, got))
+	}
+}
+
 func TestParseStationGroup_ParsesSingleRecord(t *testing.T) {
 	t.Parallel()
 	want := []rivers.WaterLevelReading{
 		{
 			Name:      "John's Bridge Nore",
-			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, time.UTC),
+			RefID:     15002,
+			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, dublin),
 			Value:     466,
 		},
 		{
 			Name:      "Dinin Bridge",
-			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, time.UTC),
+			RefID:     15003,
+			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, dublin),
 			Value:     53,
 		},
 		{
 			Name:      "Brownsbarn",
-			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, time.UTC),
+			RefID:     15006,
+			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, dublin),
 			Value:     413,
 		},
 		{
 			Name:      "Mount Juliet",
-			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, time.UTC),
+			RefID:     15011,
+			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, dublin),
 			Value:     451,
 		},
 	}
@@ -125,42 +157,50 @@ func TestParseStationGroup_ParsesMultipleRecords(t *testing.T) {
 	want := []rivers.WaterLevelReading{
 		{
 			Name:      "John's Bridge Nore",
-			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, time.UTC),
+			RefID:     15002,
+			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, dublin),
 			Value:     466,
 		},
 		{
 			Name:      "Dinin Bridge",
-			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, time.UTC),
+			RefID:     15003,
+			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, dublin),
 			Value:     53,
 		},
 		{
 			Name:      "Brownsbarn",
-			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, time.UTC),
+			RefID:     15006,
+			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, dublin),
 			Value:     413,
 		},
 		{
 			Name:      "Mount Juliet",
-			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, time.UTC),
+			RefID:     15011,
+			Timestamp: time.Date(2021, time.June, 15, 22, 00, 00, 00, dublin),
 			Value:     451,
 		},
 		{
 			Name:      "John's Bridge Nore",
-			Timestamp: time.Date(2021, time.June, 15, 22, 15, 00, 00, time.UTC),
+			RefID:     15002,
+			Timestamp: time.Date(2021, time.June, 15, 22, 15, 00, 00, dublin),
 			Value:     400,
 		},
 		{
 			Name:      "Dinin Bridge",
-			Timestamp: time.Date(2021, time.June, 15, 22, 15, 00, 00, time.UTC),
+			RefID:     15003,
+			Timestamp: time.Date(2021, time.June, 15, 22, 15, 00, 00, dublin),
 			Value:     500,
 		},
 		{
 			Name:      "Brownsbarn",
-			Timestamp: time.Date(2021, time.June, 15, 22, 15, 00, 00, time.UTC),
+			RefID:     15006,
+			Timestamp: time.Date(2021, time.June, 15, 22, 15, 00, 00, dublin),
 			Value:     400,
 		},
 		{
 			Name:      "Mount Juliet",
-			Timestamp: time.Date(2021, time.June, 15, 22, 15, 00, 00, time.UTC),
+			RefID:     15011,
+			Timestamp: time.Date(2021, time.June, 15, 22, 15, 00, 00, dublin),
 			Value:     400,
 		},
 	}
@@ -181,6 +221,8 @@ var (
 	validGroupInputMultipleRecords = `Datetime,John's Bridge Nore,Dinin Bridge,Brownsbarn,Mount Juliet
 2021-06-15 22:00,0.466,0.053,0.413,0.451
 2021-06-15 22:15,0.400,0.500,0.400,0.400`
+	validGroupInputUnknownStation = `Datetime,Unknown Bridge,Dinin Bridge
+2021-06-15 22:00,0.466,0.053`
 	invalidGroupInputNoData        = ``
 	invalidGroupInputHeaderOnly    = `Datetime,John's Bridge Nore,Dinin Bridge,Brownsbarn,Mount Juliet`
 	invalidGroupInputOneColumnOnly = `Datetime
@@ -193,3 +235,82 @@ var (
 2021-02-10 13:45,1.769
 2021-02-10 14:00,1.768`
 )
+
+func TestReadWaterLevelCSV_MapsReorderedColumnsByName(t *testing.T) {
+	t.Parallel()
+	input := `Value,Datetime
+1.772,2021-02-10 13:00
+1.771,2021-02-10 13:15`
+	got, err := rivers.ReadWaterLevelCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []rivers.WaterLevelReading{
+		{Timestamp: time.Date(2021, 2, 10, 13, 0, 0, 0, dublin), Value: 1772},
+		{Timestamp: time.Date(2021, 2, 10, 13, 15, 0, 0, dublin), Value: 1771},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestReadSensorCSV_ErrorsOnUnknownQualityColumn(t *testing.T) {
+	t.Parallel()
+	input := `datetime,value,quality
+2021-07-15 22:00,19.900,31
+2021-07-15 23:00,19.700,`
+	_, err := rivers.ReadSensorCSV(strings.NewReader(input))
+	if !errors.Is(err, rivers.ErrInvalidHeader) {
+		t.Errorf("want ErrInvalidHeader, got %v", err)
+	}
+}
+
+func TestReadWaterLevelCSV_ErrorsOnInvalidHeader(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		name  string
+		input string
+	}{
+		{name: "missing value column", input: "datetime\n2021-02-10 13:00\n"},
+		{name: "missing datetime column", input: "value\n1.772\n"},
+		{name: "unknown column", input: "datetime,value,station\n2021-02-10 13:00,1.772,01041\n"},
+		{name: "duplicated column", input: "datetime,value,value\n2021-02-10 13:00,1.772,1.771\n"},
+		{name: "positional header", input: "timestamp,level\n2021-02-10 13:00,1.772\n"},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := rivers.ReadWaterLevelCSV(strings.NewReader(tc.input))
+			if !errors.Is(err, rivers.ErrInvalidHeader) {
+				t.Errorf("want ErrInvalidHeader, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseStationGroup_MapsDatetimeColumnByName(t *testing.T) {
+	t.Parallel()
+	input := `Dinin Bridge,Datetime
+0.053,2021-06-15 22:00`
+	got, err := rivers.ReadGroupCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []rivers.WaterLevelReading{
+		{Name: "Dinin Bridge", RefID: 15003, Timestamp: time.Date(2021, 6, 15, 22, 0, 0, 0, dublin), Value: 53},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestParseStationGroup_ErrorsOnMissingDatetimeColumn(t *testing.T) {
+	t.Parallel()
+	input := `Time,Dinin Bridge
+2021-06-15 22:00,0.053`
+	_, err := rivers.ReadGroupCSV(strings.NewReader(input))
+	if !errors.Is(err, rivers.ErrInvalidHeader) {
+		t.Errorf("want ErrInvalidHeader, got %v", err)
+	}
+}
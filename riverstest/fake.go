@@ -0,0 +1,207 @@
+package riverstest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/qba73/rivers"
+)
+
+// historyKey identifies a time series held by the Fake.
+type historyKey struct {
+	station rivers.StationRef
+	sensor  rivers.SensorKind
+	period  rivers.Period
+}
+
+// Fake is an in-memory implementation of rivers.Provider.
+// It serves data set by the test, without sending HTTP requests.
+// It is safe for concurrent use.
+//
+//	fake := riverstest.NewFake()
+//	fake.SetLatestWaterLevels(readings)
+//	svc := NewService(fake) // depends on rivers.WaterLevelProvider
+type Fake struct {
+	mu       sync.Mutex
+	levels   []rivers.StationWaterLevelReading
+	readings []rivers.SensorReading
+	stations []rivers.Station
+	history  map[historyKey]rivers.TimeSeries
+	groups   map[int][]rivers.StationGroupReading
+	err      error
+	calls    int
+}
+
+var _ rivers.Provider = (*Fake)(nil)
+
+// NewFake creates a new, empty fake provider.
+func NewFake() *Fake {
+	return &Fake{
+		history: make(map[historyKey]rivers.TimeSeries),
+		groups:  make(map[int][]rivers.StationGroupReading),
+	}
+}
+
+// SetLatestWaterLevels sets readings returned by GetLatestWaterLevels.
+func (f *Fake) SetLatestWaterLevels(readings []rivers.StationWaterLevelReading) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.levels = clone(readings)
+}
+
+// SetLatestReadings sets readings returned by GetLatestReadings.
+func (f *Fake) SetLatestReadings(readings []rivers.SensorReading) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.readings = clone(readings)
+}
+
+// SetStations sets stations returned by GetStations.
+func (f *Fake) SetStations(stations []rivers.Station) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stations = clone(stations)
+}
+
+// AddHistory adds the time series returned by GetHistory for its
+// station, sensor and period. It replaces the series added before
+// for the same station, sensor and period.
+func (f *Fake) AddHistory(ts rivers.TimeSeries) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ts.Readings = clone(ts.Readings)
+	ts.Rejected = clone(ts.Rejected)
+	f.history[historyKey{ts.StationID, ts.Sensor, ts.Period}] = ts
+}
+
+// SetGroup sets readings returned for the group with the given id.
+// The group must be one of the groups returned by rivers.Groups.
+func (f *Fake) SetGroup(groupID int, readings []rivers.StationGroupReading) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.groups[groupID] = clone(readings)
+}
+
+// FailWith makes all methods return the given error.
+// Passing nil makes methods return data again.
+func (f *Fake) FailWith(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// Calls returns the number of calls made to the provider methods.
+func (f *Fake) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// call counts the call and returns the error the call should fail with.
+// It must be called with the mutex held.
+func (f *Fake) call(ctx context.Context) error {
+	f.calls++
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.err
+}
+
+// GetLatestWaterLevels returns readings set by SetLatestWaterLevels.
+func (f *Fake) GetLatestWaterLevels(ctx context.Context) ([]rivers.StationWaterLevelReading, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	return clone(f.levels), nil
+}
+
+// GetLatestReadings returns readings set by SetLatestReadings.
+func (f *Fake) GetLatestReadings(ctx context.Context) ([]rivers.SensorReading, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	return clone(f.readings), nil
+}
+
+// GetStations returns stations set by SetStations.
+func (f *Fake) GetStations(ctx context.Context) ([]rivers.Station, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	return clone(f.stations), nil
+}
+
+// GetHistory returns the time series added by AddHistory.
+// It errors with rivers.ErrStationNotFound if there is none.
+func (f *Fake) GetHistory(ctx context.Context, stationID rivers.StationRef, sensor rivers.SensorKind, period rivers.Period) (rivers.TimeSeries, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx); err != nil {
+		return rivers.TimeSeries{}, err
+	}
+	ts, ok := f.history[historyKey{stationID, sensor, period}]
+	if !ok {
+		return rivers.TimeSeries{}, fmt.Errorf("station %s, sensor %s, period %s: %w", stationID, sensor, period, rivers.ErrStationNotFound)
+	}
+	ts.Readings = clone(ts.Readings)
+	ts.Rejected = clone(ts.Rejected)
+	return ts, nil
+}
+
+// GetGroupWaterLevel returns readings set by SetGroup.
+// It errors with rivers.ErrGroupNotFound if the group does not exist.
+func (f *Fake) GetGroupWaterLevel(ctx context.Context, groupID int) ([]rivers.StationGroupReading, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	groups, err := rivers.Groups()
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if g.ID == groupID {
+			return clone(f.groups[groupID]), nil
+		}
+	}
+	return nil, fmt.Errorf("group id %d: %w", groupID, rivers.ErrGroupNotFound)
+}
+
+// GetGroupWaterLevelByName returns readings set by SetGroup for the
+// group with the given name. Names are case-insensitive.
+// It errors with rivers.ErrGroupNotFound if the group does not exist.
+func (f *Fake) GetGroupWaterLevelByName(ctx context.Context, name string) ([]rivers.StationGroupReading, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	groups, err := rivers.Groups()
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if strings.EqualFold(g.Name, strings.TrimSpace(name)) {
+			return clone(f.groups[g.ID]), nil
+		}
+	}
+	return nil, fmt.Errorf("group name %q: %w", name, rivers.ErrGroupNotFound)
+}
+
+// clone returns a copy of the slice, so callers can't
+// modify data held by the Fake.
+func clone[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
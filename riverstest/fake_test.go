@@ -0,0 +1,141 @@
+package riverstest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/rivers"
+	"github.com/qba73/rivers/riverstest"
+)
+
+func TestFake_ReturnsLatestWaterLevels(t *testing.T) {
+	t.Parallel()
+	want := []rivers.StationWaterLevelReading{
+		{StationID: 1041, Name: "Sandy Mills", Readtime: time.Date(2021, 2, 18, 6, 0, 0, 0, time.UTC), WaterLevel: 1715},
+	}
+	fake := riverstest.NewFake()
+	fake.SetLatestWaterLevels(want)
+
+	var provider rivers.WaterLevelProvider = fake
+	got, err := provider.GetLatestWaterLevels(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	got[0].WaterLevel = 0
+	again, _ := provider.GetLatestWaterLevels(context.Background())
+	if !cmp.Equal(want, again) {
+		t.Error("want fake data unaffected by changes to returned readings")
+	}
+}
+
+func TestFake_ReturnsHistoryForStationSensorAndPeriod(t *testing.T) {
+	t.Parallel()
+	want := rivers.TimeSeries{
+		StationID: 1041,
+		Sensor:    rivers.SensorTemperature,
+		Period:    rivers.PeriodWeek,
+		Readings:  []rivers.Reading{{Timestamp: time.Date(2021, 7, 15, 22, 0, 0, 0, time.UTC), Value: 19.9}},
+	}
+	fake := riverstest.NewFake()
+	fake.AddHistory(want)
+
+	got, err := fake.GetHistory(context.Background(), 1041, rivers.SensorTemperature, rivers.PeriodWeek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	_, err = fake.GetHistory(context.Background(), 1041, rivers.SensorLevel, rivers.PeriodWeek)
+	if !errors.Is(err, rivers.ErrStationNotFound) {
+		t.Errorf("want ErrStationNotFound, got %v", err)
+	}
+}
+
+func TestFake_DoesNotShareRejectedRowsWithCallers(t *testing.T) {
+	t.Parallel()
+	rejected := []rivers.RejectedRow{{Line: 3}}
+	fake := riverstest.NewFake()
+	fake.AddHistory(rivers.TimeSeries{
+		StationID: 1041,
+		Sensor:    rivers.SensorLevel,
+		Period:    rivers.PeriodDay,
+		Rejected:  rejected,
+	})
+	rejected[0].Line = 4
+
+	got, err := fake.GetHistory(context.Background(), 1041, rivers.SensorLevel, rivers.PeriodDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.Rejected[0].Line = 5
+	got, err = fake.GetHistory(context.Background(), 1041, rivers.SensorLevel, rivers.PeriodDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Rejected[0].Line != 3 {
+		t.Errorf("want rejected row on line 3, got line %d", got.Rejected[0].Line)
+	}
+}
+
+func TestFake_ReturnsGroupReadingsByIDAndName(t *testing.T) {
+	t.Parallel()
+	want := []rivers.StationGroupReading{
+		{GroupID: 1, GroupName: "Nore", StationID: 15003, Name: "Dinin Bridge", ReadingValue: 53},
+	}
+	fake := riverstest.NewFake()
+	fake.SetGroup(1, want)
+
+	got, err := fake.GetGroupWaterLevel(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	got, err = fake.GetGroupWaterLevelByName(context.Background(), "nore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	_, err = fake.GetGroupWaterLevel(context.Background(), 7)
+	if !errors.Is(err, rivers.ErrGroupNotFound) {
+		t.Errorf("want ErrGroupNotFound, got %v", err)
+	}
+}
+
+func TestFake_FailsWithGivenError(t *testing.T) {
+	t.Parallel()
+	fake := riverstest.NewFake()
+	fake.FailWith(rivers.ErrServiceUnavailable)
+
+	_, err := fake.GetStations(context.Background())
+	if !errors.Is(err, rivers.ErrServiceUnavailable) {
+		t.Errorf("want ErrServiceUnavailable, got %v", err)
+	}
+	fake.FailWith(nil)
+	if _, err := fake.GetStations(context.Background()); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+	if got := fake.Calls(); got != 2 {
+		t.Errorf("want 2 calls, got %d", got)
+	}
+}
+
+func TestFake_ErrorsOnCancelledContext(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := riverstest.NewFake().GetLatestReadings(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package riverstest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// ErrUnmatchedRequest is the error used for indicating that
+// the Replayer has no recorded response for the request.
+var ErrUnmatchedRequest = errors.New("unmatched request")
+
+// Interaction holds a request sent to the service
+// together with the response it received. The response
+// body is stored base64 encoded, so any bytes round-trip.
+type Interaction struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body"`
+}
+
+// Recorder is an http.RoundTripper that sends requests using the
+// underlying transport and records each request and response pair
+// as a line of JSON in the cassette file.
+//
+//	rec, err := riverstest.NewRecorder("testdata/latest.jsonl", nil)
+//	...
+//	defer rec.Close()
+//	client, err := rivers.NewClient(rivers.WithTransport(rec))
+type Recorder struct {
+	transport http.RoundTripper
+
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// NewRecorder creates the cassette file at the given path and returns
+// the Recorder writing to it. If transport is nil, http.DefaultTransport
+// is used to send requests. The caller should call Close when finished.
+func NewRecorder(path string, transport http.RoundTripper) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating cassette: %w", err)
+	}
+	return &Recorder{
+		transport: transport,
+		f:         f,
+		enc:       json.NewEncoder(f),
+	}, nil
+}
+
+// RoundTrip sends the request and records the received response.
+// Requests that fail with a transport error are not recorded.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("recording response body: %w", err)
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err = r.enc.Encode(Interaction{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Status: res.StatusCode,
+		Header: res.Header,
+		Body:   body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("recording interaction: %w", err)
+	}
+	return res, nil
+}
+
+// Close closes the cassette file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
+
+// Replayer is an http.RoundTripper that serves responses recorded by
+// the Recorder, without sending requests to the service.
+//
+// Requests are matched by method and URL. Recorded interactions are
+// served in the order they were recorded and each one is served once,
+// so repeated requests get consecutive responses. Requests without
+// a matching interaction fail with ErrUnmatchedRequest.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer reads interactions from the cassette file at the given path.
+func NewReplayer(path string) (*Replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening cassette: %w", err)
+	}
+	defer f.Close()
+
+	var interactions []Interaction
+	scanner := bufio.NewScanner(f)
+	// Recorded bodies are large, the latest readings feed is over 600kB.
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var i Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &i); err != nil {
+			return nil, fmt.Errorf("reading cassette line %d: %w", line, err)
+		}
+		interactions = append(interactions, i)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+	return &Replayer{
+		interactions: interactions,
+		used:         make([]bool, len(interactions)),
+	}, nil
+}
+
+// RoundTrip returns the recorded response for the request.
+// It errors with ErrUnmatchedRequest if there is none left.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	url := req.URL.String()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for n, i := range r.interactions {
+		if r.used[n] || i.Method != req.Method || i.URL != url {
+			continue
+		}
+		r.used[n] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Status, http.StatusText(i.Status)),
+			StatusCode:    i.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        i.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(i.Body)),
+			ContentLength: int64(len(i.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrUnmatchedRequest, req.Method, url)
+}
+
+// Unused returns recorded interactions that were not replayed yet.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []Interaction
+	for n, i := range r.interactions {
+		if !r.used[n] {
+			unused = append(unused, i)
+		}
+	}
+	return unused
+}
@@ -0,0 +1,165 @@
+package riverstest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/rivers"
+	"github.com/qba73/rivers/riverstest"
+)
+
+func TestReplayer_ServesRecordedResponses(t *testing.T) {
+	t.Parallel()
+	cassette := filepath.Join(t.TempDir(), "cassette.jsonl")
+	ctx := context.Background()
+
+	srv := newTestServer(t)
+	rec, err := riverstest.NewRecorder(cassette, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, srv, rivers.WithTransport(rec))
+	wantLatest, err := client.GetLatestReadings(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantHistory, err := client.GetHistory(ctx, 1041, rivers.SensorTemperature, rivers.PeriodWeek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	rep, err := riverstest.NewReplayer(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = newTestClient(t, srv, rivers.WithTransport(rep))
+	gotLatest, err := client.GetLatestReadings(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotHistory, err := client.GetHistory(ctx, 1041, rivers.SensorTemperature, rivers.PeriodWeek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(wantLatest, gotLatest) {
+		t.Error(cmp.Diff(wantLatest, gotLatest))
+	}
+	if !cmp.Equal(wantHistory, gotHistory) {
+		t.Error(cmp.Diff(wantHistory, gotHistory))
+	}
+	if unused := rep.Unused(); len(unused) != 0 {
+		t.Errorf("want all interactions replayed, got %d unused", len(unused))
+	}
+}
+
+func TestReplayer_ReplaysRecordedErrorResponses(t *testing.T) {
+	t.Parallel()
+	cassette := filepath.Join(t.TempDir(), "cassette.jsonl")
+	ctx := context.Background()
+
+	srv := newTestServer(t)
+	rec, err := riverstest.NewRecorder(cassette, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, srv, rivers.WithTransport(rec))
+	if _, err := client.GetHistory(ctx, 99999, rivers.SensorLevel, rivers.PeriodDay); err == nil {
+		t.Fatal("want error for missing station")
+	}
+	rec.Close()
+
+	rep, err := riverstest.NewReplayer(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = newTestClient(t, srv, rivers.WithTransport(rep))
+	_, err = client.GetHistory(ctx, 99999, rivers.SensorLevel, rivers.PeriodDay)
+	if !errors.Is(err, rivers.ErrStationNotFound) {
+		t.Errorf("want ErrStationNotFound, got %v", err)
+	}
+}
+
+func TestReplayer_ReplaysBinaryBodies(t *testing.T) {
+	t.Parallel()
+	// The body is not valid UTF-8.
+	want := []byte{0xff, 0xfe, 'd', 'a', 't', 'e', 0x00, 0x80}
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write(want)
+	}))
+	t.Cleanup(ts.Close)
+	cassette := filepath.Join(t.TempDir(), "cassette.jsonl")
+
+	rec, err := riverstest.NewRecorder(cassette, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := (&http.Client{Transport: rec}).Get(ts.URL + "/data/day/01041_0001.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := riverstest.NewReplayer(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = (&http.Client{Transport: rep}).Get(ts.URL + "/data/day/01041_0001.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	got, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestReplayer_ErrorsOnUnmatchedRequest(t *testing.T) {
+	t.Parallel()
+	cassette := filepath.Join(t.TempDir(), "cassette.jsonl")
+	if err := os.WriteFile(cassette, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rep, err := riverstest.NewReplayer(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestServer(t)
+	client := newTestClient(t, srv, rivers.WithTransport(rep))
+
+	_, err = client.GetLatestReadings(context.Background())
+	if !errors.Is(err, riverstest.ErrUnmatchedRequest) {
+		t.Errorf("want ErrUnmatchedRequest, got %v", err)
+	}
+	if got := srv.Requests(); got != 0 {
+		t.Errorf("want no requests sent to the server, got %d", got)
+	}
+}
+
+func TestNewReplayer_ErrorsOnInvalidCassette(t *testing.T) {
+	t.Parallel()
+	cassette := filepath.Join(t.TempDir(), "cassette.jsonl")
+	if err := os.WriteFile(cassette, []byte("{not json}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := riverstest.NewReplayer(cassette); err == nil {
+		t.Error("want error on invalid cassette")
+	}
+}
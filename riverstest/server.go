@@ -0,0 +1,206 @@
+// Package riverstest provides utilities for testing code that uses
+// the rivers package, without network access: a fixture server for
+// the waterlevel.ie service, transports recording and replaying real
+// traffic, and Fake, an in-memory implementation of rivers.Provider.
+//
+// The fixture server serves files from a directory laid out like the
+// rivers package testdata directory:
+//
+//	latest.json                 served at /geojson/latest
+//	stations.json               served at /geojson/
+//	day_01041_0001.csv          served at /data/day/01041_0001.csv
+//	week_01041_0001.csv         served at /data/week/01041_0001.csv
+//	month_01041_0001.csv        served at /data/month/01041_0001.csv
+//	group_1.csv                 served at /data/group/group_1.csv
+//
+// Hooks on the Server inject latency, 5xx errors and malformed
+// payloads, so tests can exercise retries, timeouts and parsing errors.
+package riverstest
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a fixture server for the water level service.
+type Server struct {
+	// URL is the base URL of the server,
+	// to be used as the rivers client BaseURL.
+	URL string
+
+	srv   *httptest.Server
+	files fs.FS
+
+	mu         sync.Mutex
+	latency    time.Duration
+	failures   int
+	failStatus int
+	malformed  int
+	requests   int
+}
+
+// NewServer starts and returns a new server serving fixtures
+// from the given directory. The caller should call Close when
+// finished, to shut it down.
+func NewServer(dir string) *Server {
+	s := Server{files: os.DirFS(dir)}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serve))
+	s.URL = s.srv.URL
+	return &s
+}
+
+// Close shuts down the server and blocks until all outstanding
+// requests on this server have completed.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// SetLatency delays every response by the given duration.
+// The delay ends early if the client cancels the request.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// FailNext makes the server respond to the next n requests with
+// the given status code, for example http.StatusServiceUnavailable.
+func (s *Server) FailNext(n int, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = n
+	s.failStatus = status
+}
+
+// MalformNext makes the server respond to the next n requests with
+// payloads that can't be decoded: truncated JSON documents and
+// csv files ending with an unterminated quoted field.
+func (s *Server) MalformNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.malformed = n
+}
+
+// Requests returns the number of requests received by the server.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// fault holds behaviour injected for a single request.
+type fault struct {
+	latency   time.Duration
+	status    int
+	malformed bool
+}
+
+// nextFault counts the request and returns faults to inject into it.
+func (s *Server) nextFault() fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	f := fault{latency: s.latency}
+	if s.failures > 0 {
+		s.failures--
+		f.status = s.failStatus
+		return f
+	}
+	if s.malformed > 0 {
+		s.malformed--
+		f.malformed = true
+	}
+	return f
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	f := s.nextFault()
+	if f.latency > 0 {
+		t := time.NewTimer(f.latency)
+		defer t.Stop()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-t.C:
+		}
+	}
+	if f.status != 0 {
+		http.Error(w, http.StatusText(f.status), f.status)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	name, ok := fixtureName(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	data, err := fs.ReadFile(s.files, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if f.malformed {
+		data = malform(name, data)
+	}
+	if path.Ext(name) == ".json" {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "text/csv")
+	}
+	w.Write(data)
+}
+
+// fixtureName maps the request path to the name of the fixture file.
+// It reports false if the path does not match any service endpoint.
+func fixtureName(p string) (string, bool) {
+	switch p {
+	case "/geojson/latest", "/geojson/latest/":
+		return "latest.json", true
+	case "/geojson", "/geojson/":
+		return "stations.json", true
+	}
+	dir, file := path.Split(p)
+	if path.Ext(file) != ".csv" || strings.Contains(file, "..") {
+		return "", false
+	}
+	switch dir {
+	case "/data/day/", "/data/week/", "/data/month/":
+		period := strings.Trim(strings.TrimPrefix(dir, "/data/"), "/")
+		return period + "_" + file, true
+	case "/data/group/":
+		if !strings.HasPrefix(file, "group_") {
+			return "", false
+		}
+		return file, true
+	}
+	return "", false
+}
+
+// malform breaks the payload, so decoding it fails.
+func malform(name string, data []byte) []byte {
+	if path.Ext(name) == ".json" {
+		return data[:len(data)/2]
+	}
+	var b bytes.Buffer
+	b.Write(data)
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		b.WriteByte('\n')
+	}
+	b.WriteString("\"2021-01-01 00:00,0.100\n")
+	return b.Bytes()
+}
@@ -0,0 +1,151 @@
+package riverstest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/qba73/rivers"
+	"github.com/qba73/rivers/riverstest"
+)
+
+func newTestClient(t *testing.T, srv *riverstest.Server, opts ...rivers.ClientOption) *rivers.Client {
+	t.Helper()
+	opts = append([]rivers.ClientOption{
+		rivers.WithBaseURL(srv.URL),
+		rivers.WithRetryPolicy(rivers.NoRetryPolicy()),
+		rivers.WithRateLimiter(nil),
+	}, opts...)
+	c, err := rivers.NewClient(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func newTestServer(t *testing.T) *riverstest.Server {
+	t.Helper()
+	srv := riverstest.NewServer("../testdata")
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestServer_ServesLatestReadings(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	client := newTestClient(t, srv)
+
+	got, err := client.GetLatestReadings(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) == 0 {
+		t.Error("want readings from the latest.json fixture")
+	}
+}
+
+func TestServer_ServesStations(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	client := newTestClient(t, srv)
+
+	got, err := client.GetStations(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) == 0 {
+		t.Error("want stations from the stations.json fixture")
+	}
+}
+
+func TestServer_ServesSensorHistory(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	client := newTestClient(t, srv)
+
+	for _, period := range []rivers.Period{rivers.PeriodDay, rivers.PeriodWeek, rivers.PeriodMonth} {
+		ts, err := client.GetHistory(context.Background(), 1041, rivers.SensorLevel, period)
+		if err != nil {
+			t.Fatalf("period %s: %v", period, err)
+		}
+		if len(ts.Readings) == 0 {
+			t.Errorf("period %s: want readings", period)
+		}
+	}
+}
+
+func TestServer_ServesGroupReadings(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	client := newTestClient(t, srv)
+
+	got, err := client.GetGroupWaterLevel(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Errorf("want 4 readings, got %d", len(got))
+	}
+}
+
+func TestServer_RespondsNotFoundForMissingFixture(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	client := newTestClient(t, srv)
+
+	_, err := client.GetHistory(context.Background(), 99999, rivers.SensorLevel, rivers.PeriodDay)
+	if !errors.Is(err, rivers.ErrStationNotFound) {
+		t.Errorf("want ErrStationNotFound, got %v", err)
+	}
+}
+
+func TestServer_FailsNextRequests(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	srv.FailNext(1, http.StatusServiceUnavailable)
+	client := newTestClient(t, srv)
+
+	_, err := client.GetLatestReadings(context.Background())
+	if !errors.Is(err, rivers.ErrServiceUnavailable) {
+		t.Errorf("want ErrServiceUnavailable, got %v", err)
+	}
+	if _, err := client.GetLatestReadings(context.Background()); err != nil {
+		t.Errorf("want second request to succeed, got %v", err)
+	}
+	if got := srv.Requests(); got != 2 {
+		t.Errorf("want 2 requests, got %d", got)
+	}
+}
+
+func TestServer_MalformsNextPayloads(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	srv.MalformNext(2)
+	client := newTestClient(t, srv)
+
+	if _, err := client.GetLatestReadings(context.Background()); err == nil {
+		t.Error("want error decoding malformed JSON payload")
+	}
+	if _, err := client.GetHistory(context.Background(), 1041, rivers.SensorLevel, rivers.PeriodDay); err == nil {
+		t.Error("want error parsing malformed csv payload")
+	}
+	if _, err := client.GetLatestReadings(context.Background()); err != nil {
+		t.Errorf("want third request to succeed, got %v", err)
+	}
+}
+
+func TestServer_DelaysResponses(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	srv.SetLatency(time.Second)
+	client := newTestClient(t, srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.GetLatestReadings(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want context.DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,327 @@
+package rivers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ParseMode controls how the Scanner handles records it can't parse.
+type ParseMode int
+
+const (
+	// ParseStrict stops scanning at the first record that can't be parsed.
+	ParseStrict ParseMode = iota
+
+	// ParseLenient skips records and values that can't be parsed and
+	// reports them as rejected rows.
+	ParseLenient
+)
+
+// ErrMissingValue is the error used for indicating that the sensor
+// value is empty or replaced by the "-" placeholder.
+var ErrMissingValue = errors.New("missing value")
+
+// RejectedRow holds information about a csv record, or a single value
+// in the record, that could not be parsed.
+type RejectedRow struct {
+	// Line is the line number of the record in the csv file.
+	Line int
+
+	// Column is the name of the column holding the rejected value.
+	// It is empty if the whole record is rejected.
+	Column string
+
+	// Record holds fields of the rejected record.
+	Record []string
+
+	// Err describes why the record or the value was rejected.
+	Err error
+}
+
+// rejection describes a single value rejected while parsing a record.
+type rejection struct {
+	column string
+	err    error
+}
+
+// Scanner reads readings from a csv file one record at a time,
+// without loading the whole file into memory.
+//
+// Successive calls to the Scan method step through the readings.
+// In the default strict mode scanning stops at the end of the file
+// or at the first error. In lenient mode records that can't be parsed
+// are skipped and reported by the Rejected method.
+// After Scan returns false, the Err method returns the error
+// that occurred during scanning, or nil if it was io.EOF.
+//
+//	s := rivers.NewWaterLevelScanner(f)
+//	for s.Scan() {
+//		reading := s.Reading()
+//		// process the reading
+//	}
+//	if err := s.Err(); err != nil {
+//		// handle the error
+//	}
+type Scanner[T any] struct {
+	// Mode controls how records that can't be parsed are handled.
+	// It should be set before the first call to Scan.
+	Mode ParseMode
+
+	// Location is the time zone of timestamps in the csv file.
+	// If nil, DefaultLocation is used. It should be set before
+	// the first call to Scan.
+	Location *time.Location
+
+	csv      *csv.Reader
+	header   func([]string) error
+	parse    func([]string) ([]T, []rejection, error)
+	clock    timestampParser
+	rejected []RejectedRow
+	pending  []T
+	current  T
+	started  bool
+	done     bool
+	records  int
+	err      error
+}
+
+func newScanner[T any](r io.Reader, header func([]string) error, parse func([]string) ([]T, []rejection, error)) *Scanner[T] {
+	csvreader := csv.NewReader(r)
+	csvreader.ReuseRecord = true
+	// Records with unexpected number of fields are reported by parse
+	// funcs, so truncated lines can be rejected in lenient mode.
+	csvreader.FieldsPerRecord = -1
+	return &Scanner[T]{
+		csv:    csvreader,
+		header: header,
+		parse:  parse,
+	}
+}
+
+// Scan advances the scanner to the next reading, which will then be
+// available through the Reading method. It returns false when the
+// scan stops, either by reaching the end of the file or an error.
+func (s *Scanner[T]) Scan() bool {
+	for len(s.pending) == 0 {
+		if s.done {
+			return false
+		}
+		if !s.started {
+			s.started = true
+			s.clock.loc = s.Location
+			if err := s.readHeader(); err != nil {
+				s.fail(err)
+				return false
+			}
+			continue
+		}
+		record, err := s.csv.Read()
+		if errors.Is(err, io.EOF) {
+			s.done = true
+			return false
+		}
+		if err != nil {
+			s.fail(err)
+			return false
+		}
+		s.records++
+		line, _ := s.csv.FieldPos(0)
+		readings, rejections, err := s.parse(record)
+		if err != nil {
+			if s.Mode != ParseLenient {
+				s.fail(fmt.Errorf("processing csv record on line %d: %w", line, err))
+				return false
+			}
+			s.reject(line, "", record, err)
+			continue
+		}
+		for _, r := range rejections {
+			if s.Mode == ParseLenient {
+				s.reject(line, r.column, record, r.err)
+				continue
+			}
+			// Stations that have not reported yet leave their values
+			// empty in group files. That's expected, so strict mode
+			// skips missing values instead of failing.
+			if errors.Is(r.err, ErrMissingValue) {
+				continue
+			}
+			s.fail(fmt.Errorf("processing csv record on line %d: %w", line, r.err))
+			return false
+		}
+		s.pending = readings
+	}
+	s.current, s.pending = s.pending[0], s.pending[1:]
+	return true
+}
+
+// Reading returns the most recent reading read by a call to Scan.
+func (s *Scanner[T]) Reading() T {
+	return s.current
+}
+
+// Err returns the first error encountered by the Scanner.
+func (s *Scanner[T]) Err() error {
+	return s.err
+}
+
+// Rejected returns records and values skipped so far in lenient mode.
+func (s *Scanner[T]) Rejected() []RejectedRow {
+	return s.rejected
+}
+
+func (s *Scanner[T]) reject(line int, column string, record []string, err error) {
+	s.rejected = append(s.rejected, RejectedRow{
+		Line:   line,
+		Column: column,
+		// The csv reader reuses the record slice, so we keep a copy.
+		Record: append([]string(nil), record...),
+		Err:    err,
+	})
+}
+
+func (s *Scanner[T]) readHeader() error {
+	header, err := s.csv.Read()
+	if err != nil {
+		return fmt.Errorf("reading csv file: %w", err)
+	}
+	if s.header == nil {
+		// We are not interested in the CSV header.
+		return nil
+	}
+	return s.header(header)
+}
+
+func (s *Scanner[T]) fail(err error) {
+	s.err = err
+	s.done = true
+}
+
+// collect is a helper func that reads all readings from the scanner.
+func collect[T any](s *Scanner[T]) ([]T, error) {
+	var readings []T
+	for s.Scan() {
+		readings = append(readings, s.Reading())
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return readings, nil
+}
+
+// newGaugeScanner returns a scanner reading a csv file published
+// for a single gauge. Columns are mapped by names found in the header.
+func newGaugeScanner[T any](r io.Reader, parse func(time.Time, gaugeRecord) (T, error)) *Scanner[T] {
+	var cols gaugeColumns
+	header := func(record []string) error {
+		var err error
+		cols, err = parseGaugeHeader(record)
+		return err
+	}
+	var s *Scanner[T]
+	s = newScanner(r, header, func(record []string) ([]T, []rejection, error) {
+		gr, err := cols.split(record)
+		if err != nil {
+			return nil, nil, err
+		}
+		tm, err := s.clock.parse(gr.datetime)
+		if err != nil {
+			return nil, nil, err
+		}
+		reading, err := parse(tm, gr)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []T{reading}, nil, nil
+	})
+	return s
+}
+
+// NewWaterLevelScanner returns a scanner reading water levels
+// from a csv file containing readings from a single gauge.
+func NewWaterLevelScanner(r io.Reader) *Scanner[WaterLevelReading] {
+	return newGaugeScanner(r, processWaterLevelRecord)
+}
+
+// NewWaterTemperatureScanner returns a scanner reading water temperature
+// from a csv file containing readings from a single gauge.
+func NewWaterTemperatureScanner(r io.Reader) *Scanner[WaterTemperatureReading] {
+	return newGaugeScanner(r, processWaterTempRecord)
+}
+
+// NewVoltageScanner returns a scanner reading voltage
+// from a csv file containing readings from a single gauge.
+func NewVoltageScanner(r io.Reader) *Scanner[VoltageReading] {
+	return newGaugeScanner(r, processVoltageRecord)
+}
+
+// NewSensorScanner returns a scanner reading values
+// from a csv file containing readings from any gauge sensor.
+func NewSensorScanner(r io.Reader) *Scanner[Reading] {
+	return newGaugeScanner(r, processSensorRecord)
+}
+
+// NewGroupScanner returns a scanner reading water levels from a csv
+// file containing readings from a group of stations. Each record of
+// the file holds readings from many stations, the scanner returns
+// them one by one.
+func NewGroupScanner(r io.Reader) *Scanner[WaterLevelReading] {
+	var cols groupColumns
+	header := func(record []string) error {
+		var err error
+		cols, err = parseGroupHeader(record)
+		return err
+	}
+	var s *Scanner[WaterLevelReading]
+	parse := func(record []string) ([]WaterLevelReading, []rejection, error) {
+		if len(record) != cols.count {
+			return nil, nil, fmt.Errorf("parsing station groups: invalid record %v, expecting %d fields", record, cols.count)
+		}
+		timestamp, err := s.clock.parse(record[cols.datetime])
+		if err != nil {
+			return nil, nil, err
+		}
+		var (
+			readings   []WaterLevelReading
+			rejections []rejection
+		)
+		for _, station := range cols.stations {
+			reading := record[station.column]
+			if err := checkValuePresent(reading); err != nil {
+				rejections = append(rejections, rejection{column: station.name, err: err})
+				continue
+			}
+			levelValue, err := ParseLevel(reading)
+			if err != nil {
+				rejections = append(rejections, rejection{
+					column: station.name,
+					err:    fmt.Errorf("parsing station groups: %w", err),
+				})
+				continue
+			}
+			readings = append(readings, WaterLevelReading{
+				Name:      station.name,
+				RefID:     station.refID,
+				Timestamp: timestamp,
+				Value:     levelValue,
+			})
+		}
+		return readings, rejections, nil
+	}
+	s = newScanner(r, header, parse)
+	return s
+}
+
+// checkValuePresent is a helper func that checks if the sensor value
+// is present in the record. Missing values are either empty or
+// replaced by the "-" placeholder.
+func checkValuePresent(v string) error {
+	if v = strings.TrimSpace(v); v == "" || v == "-" {
+		return ErrMissingValue
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package rivers_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/rivers"
+)
+
+func TestWaterLevelScanner_ReadsRecordsOneByOne(t *testing.T) {
+	t.Parallel()
+	s := rivers.NewWaterLevelScanner(strings.NewReader(stationData))
+
+	want := []rivers.Level{1772, 1771, 1769, 1769, 1768}
+	var got []rivers.Level
+	for s.Scan() {
+		got = append(got, s.Reading().Value)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if s.Scan() {
+		t.Error("want Scan to return false after the end of the file")
+	}
+}
+
+func TestWaterLevelScanner_StopsOnInvalidRecord(t *testing.T) {
+	t.Parallel()
+	input := `datetime,value
+2021-02-10 13:00,1.772
+2021-02-10 13:15,invalid
+2021-02-10 13:30,1.769`
+	s := rivers.NewWaterLevelScanner(strings.NewReader(input))
+
+	var got int
+	for s.Scan() {
+		got++
+	}
+	if got != 1 {
+		t.Errorf("want 1 reading before the invalid record, got %d", got)
+	}
+	if s.Err() == nil {
+		t.Error("want error on invalid record")
+	}
+}
+
+func TestWaterLevelScanner_ErrorsOnEmptyInput(t *testing.T) {
+	t.Parallel()
+	s := rivers.NewWaterLevelScanner(strings.NewReader(""))
+	if s.Scan() {
+		t.Fatal("want no readings from empty input")
+	}
+	if s.Err() == nil {
+		t.Error("want error on missing header")
+	}
+}
+
+func TestGroupScanner_ReadsEachStationReading(t *testing.T) {
+	t.Parallel()
+	s := rivers.NewGroupScanner(strings.NewReader(validGroupInputMultipleRecords))
+
+	var got []rivers.WaterLevelReading
+	for s.Scan() {
+		got = append(got, s.Reading())
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 8 {
+		t.Fatalf("want 8 readings, got %d", len(got))
+	}
+	want := rivers.WaterLevelReading{
+		Name:      "Dinin Bridge",
+		RefID:     15003,
+		Timestamp: time.Date(2021, time.June, 15, 22, 15, 00, 00, dublin),
+		Value:     500,
+	}
+	if !cmp.Equal(want, got[5]) {
+		t.Error(cmp.Diff(want, got[5]))
+	}
+}
+
+func TestSensorScanner_StreamsLargeInput(t *testing.T) {
+	t.Parallel()
+	const records = 10000
+	pr, pw := io.Pipe()
+	go func() {
+		fmt.Fprintln(pw, "datetime,value")
+		start := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < records; i++ {
+			fmt.Fprintf(pw, "%s,%.3f\n", start.Add(time.Duration(i)*15*time.Minute).Format("2006-01-02 15:04"), 13.0)
+		}
+		pw.Close()
+	}()
+
+	s := rivers.NewSensorScanner(pr)
+	var got int
+	for s.Scan() {
+		got++
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got != records {
+		t.Errorf("want %d readings, got %d", records, got)
+	}
+}
+
+func TestWaterLevelScanner_LenientModeRejectsInvalidRecords(t *testing.T) {
+	t.Parallel()
+	input := `datetime,value
+2021-02-10 13:00,1.772
+2021-02-10 13:15,
+2021-02-10 13:30,-
+2021-02-10 13:45
+2021-02-10 14:00,1.768
+not a date,1.767
+2021-02-10 14:30,1.766`
+	s := rivers.NewWaterLevelScanner(strings.NewReader(input))
+	s.Mode = rivers.ParseLenient
+
+	var got []rivers.Level
+	for s.Scan() {
+		got = append(got, s.Reading().Value)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []rivers.Level{1772, 1768, 1766}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	rejected := s.Rejected()
+	wantLines := []int{3, 4, 5, 7}
+	var gotLines []int
+	for _, r := range rejected {
+		gotLines = append(gotLines, r.Line)
+		if r.Err == nil {
+			t.Errorf("line %d: want rejection reason", r.Line)
+		}
+	}
+	if !cmp.Equal(wantLines, gotLines) {
+		t.Error(cmp.Diff(wantLines, gotLines))
+	}
+	if !errors.Is(rejected[0].Err, rivers.ErrMissingValue) || !errors.Is(rejected[1].Err, rivers.ErrMissingValue) {
+		t.Errorf("want ErrMissingValue for blank and placeholder values, got %v and %v", rejected[0].Err, rejected[1].Err)
+	}
+	wantRecord := []string{"2021-02-10 13:30", "-"}
+	if !cmp.Equal(wantRecord, rejected[1].Record) {
+		t.Error(cmp.Diff(wantRecord, rejected[1].Record))
+	}
+}
+
+func TestWaterLevelScanner_StrictModeReportsLineOfInvalidRecord(t *testing.T) {
+	t.Parallel()
+	input := `datetime,value
+2021-02-10 13:00,1.772
+2021-02-10 13:15,-`
+	_, err := rivers.ReadWaterLevelCSV(strings.NewReader(input))
+	if !errors.Is(err, rivers.ErrMissingValue) {
+		t.Fatalf("want ErrMissingValue, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("want error to report line 3, got %q", err)
+	}
+}
+
+func TestGroupScanner_LenientModeRejectsSingleValues(t *testing.T) {
+	t.Parallel()
+	input := `Datetime,John's Bridge Nore,Dinin Bridge,Brownsbarn
+2021-06-15 22:00,0.466,,0.413
+2021-06-15 22:15,0.400,abc,-`
+	s := rivers.NewGroupScanner(strings.NewReader(input))
+	s.Mode = rivers.ParseLenient
+
+	var got int
+	for s.Scan() {
+		got++
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got != 3 {
+		t.Errorf("want 3 valid readings, got %d", got)
+	}
+	type rejectedValue struct {
+		Line   int
+		Column string
+	}
+	var gotRejected []rejectedValue
+	for _, r := range s.Rejected() {
+		gotRejected = append(gotRejected, rejectedValue{r.Line, r.Column})
+	}
+	wantRejected := []rejectedValue{
+		{2, "Dinin Bridge"},
+		{3, "Dinin Bridge"},
+		{3, "Brownsbarn"},
+	}
+	if !cmp.Equal(wantRejected, gotRejected) {
+		t.Error(cmp.Diff(wantRejected, gotRejected))
+	}
+}
+
+func TestGroupScanner_StrictModeSkipsMissingValues(t *testing.T) {
+	t.Parallel()
+	input := `Datetime,John's Bridge Nore,Dinin Bridge
+2021-06-15 22:00,0.466,-`
+	got, err := rivers.ReadGroupCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Errorf("want 1 reading, got %d", len(got))
+	}
+}
@@ -1,20 +1,33 @@
 package rivers
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3" // DB diver for SQLite3
 )
 
+// sqliteTimeFormat represents the format of values
+// returned by the SQLite datetime function. The values
+// are expressed in UTC.
+const sqliteTimeFormat = "2006-01-02 15:04:05"
+
 // SQLiteStore represents a data store.
 type SQLiteStore struct {
 	DB *sql.DB
+
+	// Location is the time zone of read times returned by the store.
+	// If nil, DefaultLocation is used. Read times are stored in UTC.
+	Location *time.Location
 }
 
 // NewSQLiteStore takes a path and creates a new SQLite store.
+// The database is created if it does not exist and its schema
+// is migrated to the latest version.
 // It errors if the filepath is empty.
 func NewSQLiteStore(path string) (*SQLiteStore, error) {
 	if path == "" {
@@ -25,35 +38,123 @@ func NewSQLiteStore(path string) (*SQLiteStore, error) {
 		return nil, err
 	}
 	if err = db.Ping(); err != nil {
+		db.Close()
 		return nil, err
 	}
-	return &SQLiteStore{
+	s := SQLiteStore{
 		DB: db,
-	}, nil
+	}
+	if err := s.Migrate(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrating database schema: %w", err)
+	}
+	return &s, nil
+}
+
+// insertReading inserts a water level reading. Readings already stored
+// for the same station, sensor and time are left untouched.
+const insertReading = `INSERT INTO waterlevel_readings (station_id, station_name, sensor_ref, datetime, value, err_code)
+VALUES (?, ?, ?, datetime(?), ?, ?)
+ON CONFLICT (station_id, sensor_ref, datetime) DO NOTHING`
+
+// SaveResult reports how many readings were saved
+// and how many were skipped as duplicates.
+type SaveResult struct {
+	Inserted   int
+	Duplicates int
 }
 
 // Save takes a record representing StationWaterLevelReading and saves it in the store.
+// It errors with ErrReadingExists if the reading for the same station
+// and time is already stored.
 func (s *SQLiteStore) Save(record StationWaterLevelReading) error {
-	const query = `INSERT INTO waterlevel_readings (station_id, station_name, datetime, value) VALUES (?, ?, datetime(?), ?)`
-	tx, err := s.DB.Begin()
+	args, err := readingArgs(record)
+	if err != nil {
+		return fmt.Errorf("saving water level reading: %w", err)
+	}
+	res, err := s.DB.Exec(insertReading, args...)
+	if err != nil {
+		return fmt.Errorf("saving water level reading: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("saving water level reading: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("saving water level reading for station %s at %s: %w", record.StationID, record.Readtime, ErrReadingExists)
+	}
+	return nil
+}
+
+// SaveBatch saves all records in a single transaction. Records already
+// stored are skipped and counted as duplicates. If any record fails
+// to save, none of the records are saved.
+func (s *SQLiteStore) SaveBatch(ctx context.Context, records []StationWaterLevelReading) (SaveResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return SaveResult{}, fmt.Errorf("saving water level readings: %w", err)
 	}
-	_, err = tx.Exec(query, record.StationID, record.Name, record.Readtime, record.WaterLevel)
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, insertReading)
 	if err != nil {
-		if err := tx.Rollback(); err != nil {
-			return err
+		return SaveResult{}, fmt.Errorf("saving water level readings: %w", err)
+	}
+	defer stmt.Close()
+
+	var result SaveResult
+	for _, record := range records {
+		args, err := readingArgs(record)
+		if err != nil {
+			return SaveResult{}, fmt.Errorf("saving water level reading for station %s: %w", record.StationID, err)
+		}
+		res, err := stmt.ExecContext(ctx, args...)
+		if err != nil {
+			return SaveResult{}, fmt.Errorf("saving water level reading for station %s: %w", record.StationID, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return SaveResult{}, fmt.Errorf("saving water level reading for station %s: %w", record.StationID, err)
 		}
+		if n == 0 {
+			result.Duplicates++
+			continue
+		}
+		result.Inserted++
 	}
 	if err := tx.Commit(); err != nil {
-		return err
+		return SaveResult{}, fmt.Errorf("saving water level readings: %w", err)
+	}
+	return result, nil
+}
+
+// readingArgs returns arguments of the insertReading statement.
+func readingArgs(r StationWaterLevelReading) ([]any, error) {
+	sensor, err := storedSensorRef(r.Sensor)
+	if err != nil {
+		return nil, err
+	}
+	return []any{r.StationID.Number(), r.Name, sensor, r.Readtime, r.WaterLevel, r.ErrCode}, nil
+}
+
+// storedSensorRef returns the sensor reference stored in the
+// sensor_ref column. The store holds water levels only, so it
+// errors for sensors that do not measure the water level.
+// SensorUnknown means the water level sensor.
+func storedSensorRef(kind SensorKind) (string, error) {
+	switch kind {
+	case SensorUnknown, SensorLevel:
+		return sensorTypeLevel, nil
+	case SensorLevelOD:
+		return sensorTypeLevelOD, nil
+	default:
+		return "", fmt.Errorf("invalid sensor %q, expecting one of 'level', 'level_od'", kind)
 	}
-	return nil
 }
 
 // List returns all water level reading recorded in the database.
 func (s *SQLiteStore) List() ([]StationWaterLevelReading, error) {
-	const query = `SELECT station_id, station_name, datetime, value FROM waterlevel_readings`
+	const query = `SELECT station_id, station_name, sensor_ref, datetime, value, err_code FROM waterlevel_readings`
 	rows, err := s.DB.Query(query)
 	if err != nil {
 		return []StationWaterLevelReading{}, fmt.Errorf("executing DB query: %w", err)
@@ -63,7 +164,7 @@ func (s *SQLiteStore) List() ([]StationWaterLevelReading, error) {
 	var readings []*WaterLevel
 	for rows.Next() {
 		wl := new(WaterLevel)
-		err := rows.Scan(&wl.StationID, &wl.StationName, &wl.Datetime, &wl.Value)
+		err := rows.Scan(&wl.StationID, &wl.StationName, &wl.SensorRef, &wl.Datetime, &wl.Value, &wl.ErrCode)
 		if err != nil {
 			return []StationWaterLevelReading{}, fmt.Errorf("scanning row: %w", err)
 		}
@@ -75,36 +176,117 @@ func (s *SQLiteStore) List() ([]StationWaterLevelReading, error) {
 
 	var stationsReadings []StationWaterLevelReading
 	for _, r := range readings {
-		readTime, err := parseDatetime(r.Datetime)
+		reading, err := s.reading(*r)
 		if err != nil {
 			return []StationWaterLevelReading{}, err
 		}
-		reading := StationWaterLevelReading{
-			StationID:  r.StationID,
-			Name:       r.StationName,
-			Readtime:   readTime,
-			WaterLevel: r.Value,
-		}
 		stationsReadings = append(stationsReadings, reading)
 	}
 	return stationsReadings, nil
 }
 
 // GetLastReadingForStationID retrieves latest water level reading for given station id.
-func (s *SQLiteStore) GetLastReadingForStationID(stationID int) (StationWaterLevelReading, error) {
+func (s *SQLiteStore) GetLastReadingForStationID(stationID StationRef) (StationWaterLevelReading, error) {
 	var wl WaterLevel
-	const query = `SELECT station_id, station_name, datetime, value FROM waterlevel_readings WHERE station_id=? order by datetime desc limit 1`
+	const query = `SELECT station_id, station_name, sensor_ref, datetime, value, err_code FROM waterlevel_readings WHERE station_id=? order by datetime desc limit 1`
 
-	err := s.DB.QueryRow(query, stationID).Scan(&wl.StationID, &wl.StationName, &wl.Datetime, &wl.Value)
+	err := s.DB.QueryRow(query, stationID.Number()).Scan(&wl.StationID, &wl.StationName, &wl.SensorRef, &wl.Datetime, &wl.Value, &wl.ErrCode)
 	if errors.Is(err, sql.ErrNoRows) {
-		return StationWaterLevelReading{}, fmt.Errorf("no results for stationID %d: %w", stationID, ErrNoReading)
+		return StationWaterLevelReading{}, fmt.Errorf("no results for station %s: %w", stationID, ErrNoReading)
+	}
+	if err != nil {
+		return StationWaterLevelReading{}, fmt.Errorf("selecting last water level reading for station %s: %w", stationID, err)
+	}
+
+	return s.reading(wl)
+}
+
+// Query returns a page of readings selected by the filter. Readings
+// are ordered by read time. Pass NextCursor of the returned page in
+// the filter to fetch the next page.
+func (s *SQLiteStore) Query(ctx context.Context, filter ReadingFilter) (ReadingPage, error) {
+	sensor, err := storedSensorRef(filter.Sensor)
+	if err != nil {
+		return ReadingPage{}, fmt.Errorf("querying readings: %w", err)
+	}
+	where := []string{"sensor_ref = ?"}
+	args := []any{sensor}
+	if len(filter.StationIDs) > 0 {
+		placeholders := make([]string, len(filter.StationIDs))
+		for i, id := range filter.StationIDs {
+			placeholders[i] = "?"
+			args = append(args, id.Number())
+		}
+		where = append(where, "station_id IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if !filter.From.IsZero() {
+		where = append(where, "datetime >= ?")
+		args = append(args, filter.From.UTC().Format(sqliteTimeFormat))
+	}
+	if !filter.To.IsZero() {
+		where = append(where, "datetime < ?")
+		args = append(args, filter.To.UTC().Format(sqliteTimeFormat))
+	}
+	order, cmp := "ASC", ">"
+	if filter.Order == OrderDescending {
+		order, cmp = "DESC", "<"
+	}
+	if filter.Cursor != "" {
+		c, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return ReadingPage{}, fmt.Errorf("querying readings: %w", err)
+		}
+		where = append(where, "(datetime, id) "+cmp+" (?, ?)")
+		args = append(args, c.datetime, c.id)
+	}
+	limit := filter.Limit
+	switch {
+	case limit < 1:
+		limit = DefaultQueryLimit
+	case limit > MaxQueryLimit:
+		limit = MaxQueryLimit
 	}
+	// Fetch one more reading to tell if there is a next page.
+	args = append(args, limit+1)
+
+	query := `SELECT id, station_id, station_name, sensor_ref, datetime, value, err_code FROM waterlevel_readings WHERE ` +
+		strings.Join(where, " AND ") +
+		` ORDER BY datetime ` + order + `, id ` + order + ` LIMIT ?`
+	rows, err := s.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return StationWaterLevelReading{}, fmt.Errorf("selecting last water level reading for stationID %d: %w", stationID, err)
+		return ReadingPage{}, fmt.Errorf("querying readings: %w", err)
 	}
+	defer rows.Close()
 
-	//	Mon Jan 2 15:04:05 MST 2006
-	readTime, err := parseDatetime(wl.Datetime)
+	var (
+		page ReadingPage
+		last pageCursor
+	)
+	for rows.Next() {
+		if len(page.Readings) == limit {
+			page.NextCursor = last.encode()
+			break
+		}
+		var wl WaterLevel
+		if err := rows.Scan(&last.id, &wl.StationID, &wl.StationName, &wl.SensorRef, &wl.Datetime, &wl.Value, &wl.ErrCode); err != nil {
+			return ReadingPage{}, fmt.Errorf("scanning row: %w", err)
+		}
+		last.datetime = wl.Datetime
+		reading, err := s.reading(wl)
+		if err != nil {
+			return ReadingPage{}, err
+		}
+		page.Readings = append(page.Readings, reading)
+	}
+	if err := rows.Err(); err != nil {
+		return ReadingPage{}, fmt.Errorf("querying readings: %w", err)
+	}
+	return page, nil
+}
+
+// reading converts the database row to the water level reading.
+func (s *SQLiteStore) reading(wl WaterLevel) (StationWaterLevelReading, error) {
+	readTime, err := parseDatetime(wl.Datetime, s.Location)
 	if err != nil {
 		return StationWaterLevelReading{}, err
 	}
@@ -113,19 +295,32 @@ func (s *SQLiteStore) GetLastReadingForStationID(stationID int) (StationWaterLev
 		Name:       wl.StationName,
 		Readtime:   readTime,
 		WaterLevel: wl.Value,
+		Sensor:     parseSensorKind(wl.SensorRef),
+		ErrCode:    wl.ErrCode,
 	}, nil
 }
 
-func parseDatetime(date string) (time.Time, error) {
-	return time.Parse("2006-01-02 15:04:05", date)
+// parseDatetime parses the UTC time stored in the database
+// and converts it to the given location.
+func parseDatetime(date string, loc *time.Location) (time.Time, error) {
+	t, err := time.ParseInLocation(sqliteTimeFormat, date, time.UTC)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing datetime %q: %w", date, err)
+	}
+	if loc == nil {
+		loc = defaultLocation
+	}
+	return t.In(loc), nil
 }
 
 // WaterLevel represents water level value
 // recorded at the given time by the sensor installed
 // in the station.
 type WaterLevel struct {
-	StationID   int    `db:"station_id"`
-	StationName string `db:"station_name"`
-	Datetime    string `db:"datetime"`
-	Value       int    `db:"value"`
+	StationID   StationRef `db:"station_id"`
+	StationName string     `db:"station_name"`
+	SensorRef   string     `db:"sensor_ref"`
+	Datetime    string     `db:"datetime"`
+	Value       Level      `db:"value"`
+	ErrCode     int        `db:"err_code"`
 }
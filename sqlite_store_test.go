@@ -1,6 +1,12 @@
 package rivers_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -23,6 +29,7 @@ func TestSQLStore_RetrievesLastReadingForGivenStation(t *testing.T) {
 		Name:       "Ballybofey",
 		Readtime:   time.Date(2022, 06, 30, 04, 15, 00, 00, time.UTC),
 		WaterLevel: 879,
+		Sensor:     rivers.SensorLevel,
 	}
 	if !cmp.Equal(want, got) {
 		t.Error(cmp.Diff(want, got))
@@ -43,3 +50,429 @@ func TestSQLStore_RetrievesAllReadings(t *testing.T) {
 		t.Errorf("want %d records, got %d", want, len(got))
 	}
 }
+
+func TestSQLStore_RoundTripsReadTimeAcrossDSTChange(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(stmtEmptyDB, t)
+	store := rivers.SQLiteStore{DB: db}
+	dublin := rivers.DefaultLocation()
+	// The first and the second occurrence of 01:30 when clocks go back.
+	times := []time.Time{
+		time.Date(2021, 10, 31, 0, 30, 0, 0, time.UTC).In(dublin),
+		time.Date(2021, 10, 31, 1, 30, 0, 0, time.UTC).In(dublin),
+	}
+	for i, tm := range times {
+		err := store.Save(rivers.StationWaterLevelReading{StationID: rivers.StationRef(1043 + i), Name: "Ballybofey", Readtime: tm, WaterLevel: 879})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, want := range times {
+		got, err := store.GetLastReadingForStationID(rivers.StationRef(1043 + i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !want.Equal(got.Readtime) {
+			t.Errorf("want %v, got %v", want, got.Readtime)
+		}
+		if got.Readtime.Location() != dublin {
+			t.Errorf("want read time in %v, got %v", dublin, got.Readtime.Location())
+		}
+	}
+}
+
+func TestSQLStore_PersistsReadingErrCode(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(stmtEmptyDB, t)
+	store := rivers.SQLiteStore{DB: db}
+	want := rivers.StationWaterLevelReading{
+		StationID:  1043,
+		Name:       "Ballybofey",
+		Readtime:   time.Date(2022, 6, 30, 4, 15, 0, 0, time.UTC),
+		WaterLevel: 879,
+		Sensor:     rivers.SensorLevel,
+		ErrCode:    99,
+	}
+	if err := store.Save(want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.GetLastReadingForStationID(1043)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestSQLStore_SaveBatchReportsNewAndDuplicateReadings(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(stmtRetrieveLastReadingForOneStation, t)
+	store := rivers.SQLiteStore{DB: db}
+	records := []rivers.StationWaterLevelReading{
+		// Already stored.
+		{StationID: 1043, Name: "Ballybofey", Readtime: time.Date(2022, 6, 30, 4, 15, 0, 0, time.UTC), WaterLevel: 879},
+		{StationID: 1043, Name: "Ballybofey", Readtime: time.Date(2022, 6, 30, 4, 30, 0, 0, time.UTC), WaterLevel: 880},
+		{StationID: 3055, Name: "Glaslough", Readtime: time.Date(2022, 6, 30, 4, 30, 0, 0, time.UTC), WaterLevel: 480},
+		// Duplicated within the batch.
+		{StationID: 3055, Name: "Glaslough", Readtime: time.Date(2022, 6, 30, 4, 30, 0, 0, time.UTC), WaterLevel: 480},
+	}
+	got, err := store.SaveBatch(context.Background(), records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := rivers.SaveResult{Inserted: 2, Duplicates: 2}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	readings, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readings) != 7 {
+		t.Errorf("want 7 readings in the store, got %d", len(readings))
+	}
+}
+
+func TestSQLStore_SavesReadingsFromDifferentSensorsTakenAtTheSameTime(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(stmtEmptyDB, t)
+	store := rivers.SQLiteStore{DB: db, Location: time.UTC}
+	readtime := time.Date(2022, 6, 30, 4, 15, 0, 0, time.UTC)
+	records := []rivers.StationWaterLevelReading{
+		{StationID: 1043, Name: "Ballybofey", Readtime: readtime, WaterLevel: 879, Sensor: rivers.SensorLevel},
+		{StationID: 1043, Name: "Ballybofey", Readtime: readtime, WaterLevel: 8060, Sensor: rivers.SensorLevelOD},
+		{StationID: 1043, Name: "Ballybofey", Readtime: readtime, WaterLevel: 8060, Sensor: rivers.SensorLevelOD},
+	}
+	got, err := store.SaveBatch(context.Background(), records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := rivers.SaveResult{Inserted: 2, Duplicates: 1}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	readings, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(records[:2], readings) {
+		t.Error(cmp.Diff(records[:2], readings))
+	}
+}
+
+func TestSQLStore_SaveErrorsOnReadingFromSensorOtherThanWaterLevel(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(stmtEmptyDB, t)
+	store := rivers.SQLiteStore{DB: db}
+	record := rivers.StationWaterLevelReading{
+		StationID: 1043,
+		Name:      "Ballybofey",
+		Readtime:  time.Date(2022, 6, 30, 4, 15, 0, 0, time.UTC),
+		Sensor:    rivers.SensorTemperature,
+	}
+	if err := store.Save(record); err == nil {
+		t.Fatal("want error on temperature reading")
+	}
+	readings, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readings) != 0 {
+		t.Errorf("want no readings saved, got %d", len(readings))
+	}
+}
+
+func TestSQLStore_SaveBatchSavesNothingOnError(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(stmtEmptyDB, t)
+	store := rivers.SQLiteStore{DB: db}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	records := []rivers.StationWaterLevelReading{
+		{StationID: 1043, Name: "Ballybofey", Readtime: time.Date(2022, 6, 30, 4, 15, 0, 0, time.UTC), WaterLevel: 879},
+	}
+	if _, err := store.SaveBatch(ctx, records); err == nil {
+		t.Fatal("want error on cancelled context")
+	}
+	readings, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readings) != 0 {
+		t.Errorf("want no readings saved, got %d", len(readings))
+	}
+}
+
+func TestSQLStore_SaveErrorsOnDuplicateReading(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(stmtRetrieveLastReadingForOneStation, t)
+	store := rivers.SQLiteStore{DB: db}
+	// The reading is stored, but it is not the last one for the station.
+	record := rivers.StationWaterLevelReading{StationID: 1043, Name: "Ballybofey", Readtime: time.Date(2022, 6, 28, 4, 15, 0, 0, time.UTC), WaterLevel: 679}
+	err := store.Save(record)
+	if !errors.Is(err, rivers.ErrReadingExists) {
+		t.Errorf("want ErrReadingExists, got %v", err)
+	}
+}
+
+func TestSQLStore_ConcurrentWritersDoNotDuplicateReadings(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "waterlevels.db")
+	// Wait for the lock held by the other writer instead of failing.
+	dsn := path + "?_busy_timeout=5000"
+	var stores []*rivers.SQLiteStore
+	for i := 0; i < 2; i++ {
+		s, err := rivers.NewSQLiteStore(dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.DB.Close() })
+		stores = append(stores, s)
+	}
+	var records []rivers.StationWaterLevelReading
+	for i := 0; i < 100; i++ {
+		records = append(records, rivers.StationWaterLevelReading{
+			StationID:  rivers.StationRef(1000 + i),
+			Name:       "Station",
+			Readtime:   time.Date(2022, 6, 30, 4, 15, 0, 0, time.UTC),
+			WaterLevel: 879,
+		})
+	}
+
+	results := make([]rivers.SaveResult, len(stores))
+	errs := make([]error, len(stores))
+	var wg sync.WaitGroup
+	for i, s := range stores {
+		wg.Add(1)
+		go func(i int, s *rivers.SQLiteStore) {
+			defer wg.Done()
+			results[i], errs[i] = s.SaveBatch(context.Background(), records)
+		}(i, s)
+	}
+	wg.Wait()
+
+	var inserted, duplicates int
+	for i := range stores {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		inserted += results[i].Inserted
+		duplicates += results[i].Duplicates
+	}
+	if inserted != 100 || duplicates != 100 {
+		t.Errorf("want 100 inserted and 100 duplicates, got %d and %d", inserted, duplicates)
+	}
+}
+
+// newQueryTestStore returns the store holding readings taken every
+// hour from 2022-06-28 00:00 UTC to 2022-07-04 23:00 UTC by stations
+// 1041, 1043 and 3055.
+func newQueryTestStore(t *testing.T) *rivers.SQLiteStore {
+	t.Helper()
+	db := newTestDB(stmtEmptyDB, t)
+	store := rivers.SQLiteStore{DB: db, Location: time.UTC}
+	var records []rivers.StationWaterLevelReading
+	start := time.Date(2022, 6, 28, 0, 0, 0, 0, time.UTC)
+	for h := 0; h < 7*24; h++ {
+		for _, id := range []rivers.StationRef{1041, 1043, 3055} {
+			records = append(records, rivers.StationWaterLevelReading{
+				StationID:  id,
+				Name:       "Station",
+				Readtime:   start.Add(time.Duration(h) * time.Hour),
+				WaterLevel: rivers.Level(h),
+			})
+		}
+	}
+	if _, err := store.SaveBatch(context.Background(), records); err != nil {
+		t.Fatal(err)
+	}
+	return &store
+}
+
+func TestSQLStore_QueryReturnsReadingsForStationInTimeRange(t *testing.T) {
+	t.Parallel()
+	store := newQueryTestStore(t)
+	page, err := store.Query(context.Background(), rivers.ReadingFilter{
+		StationIDs: []rivers.StationRef{1043},
+		From:       time.Date(2022, 6, 30, 10, 0, 0, 0, time.UTC),
+		To:         time.Date(2022, 6, 30, 13, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []rivers.StationWaterLevelReading{
+		{StationID: 1043, Name: "Station", Readtime: time.Date(2022, 6, 30, 10, 0, 0, 0, time.UTC), WaterLevel: 58, Sensor: rivers.SensorLevel},
+		{StationID: 1043, Name: "Station", Readtime: time.Date(2022, 6, 30, 11, 0, 0, 0, time.UTC), WaterLevel: 59, Sensor: rivers.SensorLevel},
+		{StationID: 1043, Name: "Station", Readtime: time.Date(2022, 6, 30, 12, 0, 0, 0, time.UTC), WaterLevel: 60, Sensor: rivers.SensorLevel},
+	}
+	if !cmp.Equal(want, page.Readings) {
+		t.Error(cmp.Diff(want, page.Readings))
+	}
+	if page.NextCursor != "" {
+		t.Errorf("want no next page, got cursor %q", page.NextCursor)
+	}
+}
+
+func TestSQLStore_QueryOrdersReadingsDescending(t *testing.T) {
+	t.Parallel()
+	store := newQueryTestStore(t)
+	page, err := store.Query(context.Background(), rivers.ReadingFilter{
+		StationIDs: []rivers.StationRef{1041, 3055},
+		Order:      rivers.OrderDescending,
+		Limit:      3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Readings taken at the same time are ordered by insertion.
+	want := []rivers.StationWaterLevelReading{
+		{StationID: 3055, Name: "Station", Readtime: time.Date(2022, 7, 4, 23, 0, 0, 0, time.UTC), WaterLevel: 167, Sensor: rivers.SensorLevel},
+		{StationID: 1041, Name: "Station", Readtime: time.Date(2022, 7, 4, 23, 0, 0, 0, time.UTC), WaterLevel: 167, Sensor: rivers.SensorLevel},
+		{StationID: 3055, Name: "Station", Readtime: time.Date(2022, 7, 4, 22, 0, 0, 0, time.UTC), WaterLevel: 166, Sensor: rivers.SensorLevel},
+	}
+	if !cmp.Equal(want, page.Readings) {
+		t.Error(cmp.Diff(want, page.Readings))
+	}
+	if page.NextCursor == "" {
+		t.Error("want cursor for the next page")
+	}
+}
+
+func TestSQLStore_QueryPagesThroughAllReadings(t *testing.T) {
+	t.Parallel()
+	orders := map[string]rivers.SortOrder{
+		"ascending":  rivers.OrderAscending,
+		"descending": rivers.OrderDescending,
+	}
+	for name, order := range orders {
+		order := order
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			store := newQueryTestStore(t)
+			filter := rivers.ReadingFilter{
+				StationIDs: []rivers.StationRef{1043, 3055},
+				From:       time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC),
+				Order:      order,
+				Limit:      50,
+			}
+			var got []rivers.StationWaterLevelReading
+			pages := 0
+			for {
+				page, err := store.Query(context.Background(), filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				pages++
+				got = append(got, page.Readings...)
+				if page.NextCursor == "" {
+					break
+				}
+				filter.Cursor = page.NextCursor
+			}
+			// 4 days of hourly readings from 2 stations.
+			if len(got) != 192 {
+				t.Fatalf("want 192 readings, got %d", len(got))
+			}
+			if pages != 4 {
+				t.Errorf("want 4 pages, got %d", pages)
+			}
+			seen := make(map[string]bool)
+			for i, r := range got {
+				key := fmt.Sprint(r.StationID, r.Readtime)
+				if seen[key] {
+					t.Fatalf("reading %s returned twice", key)
+				}
+				seen[key] = true
+				if i == 0 {
+					continue
+				}
+				prev := got[i-1].Readtime
+				if order == rivers.OrderAscending && r.Readtime.Before(prev) ||
+					order == rivers.OrderDescending && r.Readtime.After(prev) {
+					t.Fatalf("reading %d out of order: %s after %s", i, r.Readtime, prev)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLStore_QueryFiltersReadingsBySensor(t *testing.T) {
+	t.Parallel()
+	store := newQueryTestStore(t)
+	readtime := time.Date(2022, 6, 30, 10, 0, 0, 0, time.UTC)
+	levelOD := rivers.StationWaterLevelReading{
+		StationID:  1043,
+		Name:       "Station",
+		Readtime:   readtime,
+		WaterLevel: 8060,
+		Sensor:     rivers.SensorLevelOD,
+	}
+	if err := store.Save(levelOD); err != nil {
+		t.Fatal(err)
+	}
+	page, err := store.Query(context.Background(), rivers.ReadingFilter{
+		Sensor: rivers.SensorLevelOD,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []rivers.StationWaterLevelReading{levelOD}
+	if !cmp.Equal(want, page.Readings) {
+		t.Error(cmp.Diff(want, page.Readings))
+	}
+
+	page, err = store.Query(context.Background(), rivers.ReadingFilter{
+		StationIDs: []rivers.StationRef{1043},
+		Sensor:     rivers.SensorLevel,
+		From:       readtime,
+		To:         readtime.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Readings) != 1 || page.Readings[0].Sensor != rivers.SensorLevel {
+		t.Errorf("want one water level reading, got %v", page.Readings)
+	}
+}
+
+func TestSQLStore_QueryLimitsPageSize(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(stmtEmptyDB, t)
+	store := rivers.SQLiteStore{DB: db}
+	start := time.Date(2022, 6, 28, 0, 0, 0, 0, time.UTC)
+	var records []rivers.StationWaterLevelReading
+	for i := 0; i <= rivers.MaxQueryLimit; i++ {
+		records = append(records, rivers.StationWaterLevelReading{
+			StationID: 1043,
+			Name:      "Ballybofey",
+			Readtime:  start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	if _, err := store.SaveBatch(context.Background(), records); err != nil {
+		t.Fatal(err)
+	}
+	page, err := store.Query(context.Background(), rivers.ReadingFilter{
+		Limit: math.MaxInt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Readings) != rivers.MaxQueryLimit {
+		t.Errorf("want %d readings, got %d", rivers.MaxQueryLimit, len(page.Readings))
+	}
+	if page.NextCursor == "" {
+		t.Error("want cursor for the next page")
+	}
+}
+
+func TestSQLStore_QueryErrorsOnInvalidCursor(t *testing.T) {
+	t.Parallel()
+	store := newQueryTestStore(t)
+	_, err := store.Query(context.Background(), rivers.ReadingFilter{
+		Cursor: "bogus",
+	})
+	if !errors.Is(err, rivers.ErrInvalidCursor) {
+		t.Errorf("want ErrInvalidCursor, got %v", err)
+	}
+}
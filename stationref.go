@@ -0,0 +1,121 @@
+package rivers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxStationRef is the highest station number. Station numbers
+// have five digits, the first two identify the hydrometric area.
+const maxStationRef = 99999
+
+// ErrInvalidStationRef is the error used for indicating that
+// the string does not represent a valid station reference.
+var ErrInvalidStationRef = errors.New("invalid station reference")
+
+// StationRef identifies a station by its number,
+// for example 1041 for the Sandy Mills station.
+//
+// The water level service pads station numbers differently depending
+// on the context: the GeoJSON feed uses ten digits ("0000001041") and
+// csv file names use five digits ("01041"). ParseStationRef accepts
+// all forms and the StationRef methods render them back.
+// The zero value means the station is not known.
+type StationRef int
+
+// ParseStationRef parses the station reference in any form used
+// by the water level service. It errors if the string is not
+// a number or the number is out of the station numbers range.
+func ParseStationRef(s string) (StationRef, error) {
+	v := strings.TrimSpace(s)
+	if v == "" || strings.TrimLeft(v, "0123456789") != "" {
+		return 0, fmt.Errorf("%w %q", ErrInvalidStationRef, s)
+	}
+	n, err := strconv.Atoi(strings.TrimLeft(v, "0"))
+	if err != nil || n < 1 || n > maxStationRef {
+		return 0, fmt.Errorf("%w %q", ErrInvalidStationRef, s)
+	}
+	return StationRef(n), nil
+}
+
+// MustParseStationRef is like ParseStationRef but panics
+// if the string can't be parsed. It is intended for use
+// with station references known at compile time.
+func MustParseStationRef(s string) StationRef {
+	ref, err := ParseStationRef(s)
+	if err != nil {
+		panic(err)
+	}
+	return ref
+}
+
+// IsZero reports whether the station is not known.
+func (r StationRef) IsZero() bool {
+	return r == 0
+}
+
+// Number returns the station number, as stored in the database.
+func (r StationRef) Number() int {
+	return int(r)
+}
+
+// String returns the station reference padded to five digits,
+// as used in csv file names, for example "01041".
+func (r StationRef) String() string {
+	return fmt.Sprintf("%05d", int(r))
+}
+
+// GeoJSON returns the station reference padded to ten digits,
+// as used in the GeoJSON feed, for example "0000001041".
+func (r StationRef) GeoJSON() string {
+	return fmt.Sprintf("%010d", int(r))
+}
+
+// jsonString returns the station reference padded as in the GeoJSON
+// feed, or an empty string if the station is not known. Stations,
+// sensors and group readings carry station IDs in this form in JSON.
+func (r StationRef) jsonString() string {
+	if r.IsZero() {
+		return ""
+	}
+	return r.GeoJSON()
+}
+
+// validate checks if the reference is in the station numbers range.
+func (r StationRef) validate() error {
+	if r < 1 || r > maxStationRef {
+		return fmt.Errorf("%w %d", ErrInvalidStationRef, int(r))
+	}
+	return nil
+}
+
+// MarshalJSON encodes the station reference as a JSON number.
+func (r StationRef) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Itoa(int(r))), nil
+}
+
+// UnmarshalJSON decodes the station reference from a JSON number
+// or a JSON string holding the reference in any padded form.
+func (r *StationRef) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+	if s == "" {
+		*r = 0
+		return nil
+	}
+	ref, err := ParseStationRef(s)
+	if err != nil {
+		return err
+	}
+	*r = ref
+	return nil
+}
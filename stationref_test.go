@@ -0,0 +1,152 @@
+package rivers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/rivers"
+)
+
+func TestParseStationRef_AcceptsAllUpstreamForms(t *testing.T) {
+	t.Parallel()
+	for _, input := range []string{"0000001041", "01041", "1041", " 01041 "} {
+		got, err := rivers.ParseStationRef(input)
+		if err != nil {
+			t.Fatalf("ParseStationRef(%q): %v", input, err)
+		}
+		if got != 1041 {
+			t.Errorf("ParseStationRef(%q): want 1041, got %d", input, got)
+		}
+	}
+}
+
+func TestParseStationRef_ErrorsOnInvalidInput(t *testing.T) {
+	t.Parallel()
+	for _, input := range []string{"", "0", "00000", "-1041", "1041a", "Sandy Mills", "100000", "99999999999999999999"} {
+		_, err := rivers.ParseStationRef(input)
+		if !errors.Is(err, rivers.ErrInvalidStationRef) {
+			t.Errorf("ParseStationRef(%q): want ErrInvalidStationRef, got %v", input, err)
+		}
+	}
+}
+
+func TestStationRef_RendersPaddingForEachContext(t *testing.T) {
+	t.Parallel()
+	ref := rivers.StationRef(1041)
+	if got := ref.String(); got != "01041" {
+		t.Errorf("want csv form %q, got %q", "01041", got)
+	}
+	if got := ref.GeoJSON(); got != "0000001041" {
+		t.Errorf("want GeoJSON form %q, got %q", "0000001041", got)
+	}
+	if got := ref.Number(); got != 1041 {
+		t.Errorf("want number 1041, got %d", got)
+	}
+}
+
+func TestStationRef_DecodesJSONNumbersAndStrings(t *testing.T) {
+	t.Parallel()
+	var got []rivers.StationRef
+	err := json.Unmarshal([]byte(`[1041, "0000001043", "03055"]`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []rivers.StationRef{1041, 1043, 3055}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "[1041,1043,3055]" {
+		t.Errorf("want station refs encoded as numbers, got %s", data)
+	}
+}
+
+func TestStationGroupReading_EncodesStationIDAsPaddedString(t *testing.T) {
+	t.Parallel()
+	readings := []rivers.StationGroupReading{
+		{GroupID: 1, GroupName: "Boyne", StationID: 7012, Readtime: time.Date(2022, 6, 30, 4, 15, 0, 0, time.UTC), ReadingValue: 879},
+		{GroupID: 1, GroupName: "Boyne", Readtime: time.Date(2022, 6, 30, 4, 15, 0, 0, time.UTC), ReadingValue: 480},
+	}
+	data, err := json.Marshal(readings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"group_id":1,"group_name":"Boyne","station_id":"0000007012","readtime":"2022-06-30T04:15:00Z","reading_value":879},` +
+		`{"group_id":1,"group_name":"Boyne","station_id":"","readtime":"2022-06-30T04:15:00Z","reading_value":480}]`
+	if string(data) != want {
+		t.Errorf("want %s, got %s", want, data)
+	}
+	var got []rivers.StationGroupReading
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(readings, got) {
+		t.Error(cmp.Diff(readings, got))
+	}
+}
+
+func TestStation_EncodesIDsAsPaddedStrings(t *testing.T) {
+	t.Parallel()
+	station := rivers.Station{
+		ID:       1041,
+		Name:     "Sandy Mills",
+		RegionID: 3,
+		Lat:      54.838318,
+		Long:     -7.575758,
+		Sensors: []rivers.Sensor{
+			{StationID: 1041, StationName: "Sandy Mills", Type: "0001"},
+		},
+	}
+	data, err := json.Marshal(station)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":"0000001041","name":"Sandy Mills","region_id":3,"region_name":"","lat":54.838318,"long":-7.575758,` +
+		`"sensors":[{"station_id":"0000001041","station_name":"Sandy Mills","type":"0001","value":"","timestamp":"","err_code":0,"region_id":""}]}`
+	if string(data) != want {
+		t.Errorf("want %s, got %s", want, data)
+	}
+	var got rivers.Station
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(station, got) {
+		t.Error(cmp.Diff(station, got))
+	}
+}
+
+func TestSensor_EncodesUnknownStationAsEmptyString(t *testing.T) {
+	t.Parallel()
+	data, err := json.Marshal(rivers.Sensor{StationName: "Sandy Mills", Type: "0001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"station_id":"","station_name":"Sandy Mills","type":"0001","value":"","timestamp":"","err_code":0,"region_id":""}`
+	if string(data) != want {
+		t.Errorf("want %s, got %s", want, data)
+	}
+}
+
+func TestStationRef_DecodingErrorsOnInvalidReference(t *testing.T) {
+	t.Parallel()
+	var got rivers.StationRef
+	if err := json.Unmarshal([]byte(`"abc"`), &got); !errors.Is(err, rivers.ErrInvalidStationRef) {
+		t.Errorf("want ErrInvalidStationRef, got %v", err)
+	}
+}
+
+func TestRiversClient_GetHistoryErrorsOnInvalidStationRef(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(t)
+	_, err := client.GetHistory(context.Background(), 0, rivers.SensorLevel, rivers.PeriodDay)
+	if !errors.Is(err, rivers.ErrInvalidStationRef) {
+		t.Errorf("want ErrInvalidStationRef, got %v", err)
+	}
+}
@@ -1,15 +1,26 @@
 package rivers
 
-// Store is the interface that wraps Save,
-// GetLastReadingForStationID and List methods.
+import "context"
+
+// Store is the interface that wraps Save, SaveBatch,
+// GetLastReadingForStationID, List and Query methods.
 type Store interface {
 	// Save takes a record and stores it in the store.
+	// It errors with ErrReadingExists if the record is already stored.
 	Save(StationWaterLevelReading) error
 
+	// SaveBatch takes records and stores them at once, skipping
+	// records already stored. It reports how many records were new
+	// and how many were duplicates.
+	SaveBatch(context.Context, []StationWaterLevelReading) (SaveResult, error)
+
 	// GetLastReadingsForStationID takes station ID and returns
 	// last recorded reading.
-	GetLastReadingForStationID(int) (StationWaterLevelReading, error)
+	GetLastReadingForStationID(StationRef) (StationWaterLevelReading, error)
 
 	// List returns all readings from the store.
 	List() ([]StationWaterLevelReading, error)
+
+	// Query returns a page of readings selected by the filter.
+	Query(context.Context, ReadingFilter) (ReadingPage, error)
 }
@@ -0,0 +1,66 @@
+package rivers
+
+import (
+	"fmt"
+	"time"
+
+	// Embed the time zone database, so timestamps are parsed
+	// the same way on hosts without zoneinfo files installed.
+	_ "time/tzdata"
+)
+
+// defaultLocationName is the name of the time zone used
+// by the water level service in csv data files.
+const defaultLocationName = "Europe/Dublin"
+
+var defaultLocation = mustLoadLocation(defaultLocationName)
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(fmt.Sprintf("loading time zone %s: %v", name, err))
+	}
+	return loc
+}
+
+// DefaultLocation returns the time zone of timestamps in csv files
+// published by the water level service. Timestamps are expressed
+// in Irish local time, which is GMT in winter and IST in summer.
+func DefaultLocation() *time.Location {
+	return defaultLocation
+}
+
+// timestampParser parses local timestamps of consecutive readings.
+//
+// Wall clock times repeat when clocks go back in autumn, so a timestamp
+// like "2021-10-31 01:30" is ambiguous. The parser picks the earlier
+// instant, unless it does not follow the previous reading, which means
+// the file already moved to the repeated hour.
+type timestampParser struct {
+	loc  *time.Location
+	prev time.Time
+}
+
+func (p *timestampParser) parse(v string) (time.Time, error) {
+	loc := p.loc
+	if loc == nil {
+		loc = defaultLocation
+	}
+	t, err := time.ParseInLocation(gaugeTimeFormat, v, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// time.ParseInLocation resolves ambiguous times to the later
+	// instant. Check if the same wall clock time happened earlier,
+	// that is before the clocks went back.
+	_, offset := t.Zone()
+	_, offsetBefore := t.Add(-24 * time.Hour).Zone()
+	if shift := time.Duration(offsetBefore-offset) * time.Second; shift > 0 {
+		earlier := t.Add(-shift)
+		if earlier.Format(gaugeTimeFormat) == v && (p.prev.IsZero() || earlier.After(p.prev)) {
+			t = earlier
+		}
+	}
+	p.prev = t
+	return t, nil
+}
@@ -0,0 +1,124 @@
+package rivers_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/rivers"
+)
+
+func TestReadSensorCSV_ParsesTimestampsInIrishTime(t *testing.T) {
+	t.Parallel()
+	input := `datetime,value
+2021-01-15 12:00,1.0
+2021-07-15 12:00,2.0`
+	got, err := rivers.ReadSensorCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{
+		// GMT in winter.
+		time.Date(2021, 1, 15, 12, 0, 0, 0, time.UTC),
+		// IST in summer, one hour ahead of UTC.
+		time.Date(2021, 7, 15, 11, 0, 0, 0, time.UTC),
+	}
+	if !cmp.Equal(want, timestamps(got)) {
+		t.Error(cmp.Diff(want, timestamps(got)))
+	}
+}
+
+func TestReadSensorCSV_ParsesTimestampsWhenClocksGoForward(t *testing.T) {
+	t.Parallel()
+	// Clocks go forward from 01:00 GMT to 02:00 IST on 28 March 2021.
+	input := `datetime,value
+2021-03-28 00:45,1.0
+2021-03-28 02:00,2.0
+2021-03-28 02:15,3.0`
+	got, err := rivers.ReadSensorCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{
+		time.Date(2021, 3, 28, 0, 45, 0, 0, time.UTC),
+		time.Date(2021, 3, 28, 1, 0, 0, 0, time.UTC),
+		time.Date(2021, 3, 28, 1, 15, 0, 0, time.UTC),
+	}
+	if !cmp.Equal(want, timestamps(got)) {
+		t.Error(cmp.Diff(want, timestamps(got)))
+	}
+}
+
+func TestReadSensorCSV_ParsesRepeatedHourWhenClocksGoBack(t *testing.T) {
+	t.Parallel()
+	// Clocks go back from 02:00 IST to 01:00 GMT on 31 October 2021,
+	// so wall clock times between 01:00 and 02:00 happen twice.
+	input := `datetime,value
+2021-10-31 00:30,1.0
+2021-10-31 01:00,2.0
+2021-10-31 01:30,3.0
+2021-10-31 01:00,4.0
+2021-10-31 01:30,5.0
+2021-10-31 02:00,6.0`
+	got, err := rivers.ReadSensorCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{
+		time.Date(2021, 10, 30, 23, 30, 0, 0, time.UTC),
+		time.Date(2021, 10, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 10, 31, 0, 30, 0, 0, time.UTC),
+		time.Date(2021, 10, 31, 1, 0, 0, 0, time.UTC),
+		time.Date(2021, 10, 31, 1, 30, 0, 0, time.UTC),
+		time.Date(2021, 10, 31, 2, 0, 0, 0, time.UTC),
+	}
+	if !cmp.Equal(want, timestamps(got)) {
+		t.Error(cmp.Diff(want, timestamps(got)))
+	}
+}
+
+func TestSensorScanner_UsesConfiguredLocation(t *testing.T) {
+	t.Parallel()
+	input := `datetime,value
+2021-07-15 12:00,1.0`
+	s := rivers.NewSensorScanner(strings.NewReader(input))
+	s.Location = time.UTC
+	if !s.Scan() {
+		t.Fatal(s.Err())
+	}
+	want := time.Date(2021, 7, 15, 12, 0, 0, 0, time.UTC)
+	if got := s.Reading().Timestamp; !want.Equal(got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestGroupScanner_ParsesTimestampsInIrishTime(t *testing.T) {
+	t.Parallel()
+	input := `Datetime,Dinin Bridge
+2021-06-15 22:00,0.053`
+	got, err := rivers.ReadGroupCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2021, 6, 15, 21, 0, 0, 0, time.UTC)
+	if !want.Equal(got[0].Timestamp) {
+		t.Errorf("want %v, got %v", want, got[0].Timestamp)
+	}
+}
+
+func TestNewClient_ErrorsOnNilLocation(t *testing.T) {
+	t.Parallel()
+	_, err := rivers.NewClient(rivers.WithLocation(nil))
+	if err == nil {
+		t.Error("want error on nil location")
+	}
+}
+
+func timestamps(readings []rivers.Reading) []time.Time {
+	var ts []time.Time
+	for _, r := range readings {
+		ts = append(ts, r.Timestamp)
+	}
+	return ts
+}